@@ -0,0 +1,9 @@
+// run once by the mongo-init service, mongo1 gets the highest priority so it stays the primary the examples connect to
+rs.initiate({
+  _id: "rs0",
+  members: [
+    { _id: 0, host: "mongo1:27017", priority: 2 },
+    { _id: 1, host: "mongo2:27017" },
+    { _id: 2, host: "mongo3:27017" }
+  ]
+})
@@ -0,0 +1,30 @@
+#!/bin/bash
+# initiates the config server and shard replica sets, adds shard1 and shard2 to mongos ( shard3 is left to terraform ) and creates the root user through mongos
+set -e
+
+wait_for() {
+  until mongo --quiet --host "$1" --eval 'db.adminCommand({ ping: 1 })' > /dev/null 2>&1; do
+    sleep 1
+  done
+}
+
+initiate() {
+  wait_for "$2"
+  mongo --quiet --host "$2" --eval "
+    if (db.adminCommand({ replSetGetStatus: 1 }).codeName == 'NotYetInitialized') {
+      rs.initiate({ _id: '$1', configsvr: $3, members: [{ _id: 0, host: '$2:27017' }] })
+    }"
+}
+
+initiate cfg cfg true
+initiate shard1 shard1 false
+initiate shard2 shard2 false
+initiate shard3 shard3 false
+
+wait_for mongos
+mongo --quiet --host mongos --eval "
+  sh.addShard('shard1/shard1:27017')
+  sh.addShard('shard2/shard2:27017')
+  if (db.getSiblingDB('admin').getUser('root') == null) {
+    db.getSiblingDB('admin').createUser({ user: 'root', pwd: 'root', roles: ['root'] })
+  }"
@@ -0,0 +1,41 @@
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    mongodb = {
+      source = "registry.terraform.io/Kaginari/mongodb"
+      version = "9.9.9"
+    }
+  }
+}
+## docker-compose -f docker/docker-compose-replicaset.yml up -d
+provider "mongodb" {
+  host = "127.0.0.1"
+  port = "27017"
+  direct = true # the members are only known by their compose names, connect to mongo1 alone
+  username = "root"
+  password = "root"
+  auth_database = "admin"
+}
+resource "mongodb_replica_set_config" "rs0" {
+  member {
+    host = "mongo1:27017"
+    priority = 2
+    tags = {
+      dc = "east"
+    }
+  }
+  member {
+    host = "mongo2:27017"
+    tags = {
+      dc = "west"
+    }
+  }
+  member {
+    host = "mongo3:27017"
+    priority = 0
+    votes = 0
+    hidden = true
+    secondary_delay_secs = 3600
+  }
+}
@@ -0,0 +1,34 @@
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    mongodb = {
+      source = "registry.terraform.io/Kaginari/mongodb"
+      version = "9.9.9"
+    }
+  }
+}
+## docker-compose -f docker/docker-compose-replicaset.yml up -d mongo1 mongo2 mongo3
+provider "mongodb" {
+  host = "127.0.0.1"
+  port = "27017"
+  username = "root"
+  password = "root"
+  auth_database = "admin"
+}
+resource "mongodb_replica_set_initiate" "rs0" {
+  set_name = "rs0"
+  member {
+    host = "mongo1:27017"
+    priority = 2
+  }
+  member {
+    host = "mongo2:27017"
+  }
+  member {
+    host = "mongo3:27017"
+  }
+  timeouts {
+    create = "2m"
+  }
+}
@@ -0,0 +1,24 @@
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    mongodb = {
+      source = "registry.terraform.io/Kaginari/mongodb"
+      version = "9.9.9"
+    }
+  }
+}
+## docker-compose -f docker/docker-compose-sharding.yml up -d
+provider "mongodb" {
+  host = "127.0.0.1"
+  port = "27017" # mongos
+  username = "root"
+  password = "root"
+  auth_database = "admin"
+}
+resource "mongodb_shard" "shard3" {
+  connection_string = "shard3/shard3:27017"
+  timeouts {
+    delete = "30m"
+  }
+}
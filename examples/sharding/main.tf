@@ -0,0 +1,49 @@
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    mongodb = {
+      source = "registry.terraform.io/Kaginari/mongodb"
+      version = "9.9.9"
+    }
+  }
+}
+## docker-compose -f docker/docker-compose-sharding.yml up -d
+provider "mongodb" {
+  host = "127.0.0.1"
+  port = "27017" # mongos
+  username = "root"
+  password = "root"
+  auth_database = "admin"
+}
+resource "mongodb_database_sharding" "shop" {
+  database = "shop"
+}
+resource "mongodb_shard_collection" "orders" {
+  database = mongodb_database_sharding.shop.database
+  collection = "orders"
+  # written by hand, jsonencode would sort the fields
+  key = "{\"region\": 1, \"_id\": 1}"
+}
+resource "mongodb_shard_zone" "eu" {
+  shard = "shard1"
+  zone = "EU"
+}
+resource "mongodb_shard_zone" "us" {
+  shard = "shard2"
+  zone = "US"
+}
+resource "mongodb_zone_key_range" "eu" {
+  database = mongodb_database_sharding.shop.database
+  collection = mongodb_shard_collection.orders.collection
+  min = "{\"region\": \"EU\", \"_id\": {\"$minKey\": 1}}"
+  max = "{\"region\": \"EU\", \"_id\": {\"$maxKey\": 1}}"
+  zone = mongodb_shard_zone.eu.zone
+}
+resource "mongodb_zone_key_range" "us" {
+  database = mongodb_database_sharding.shop.database
+  collection = mongodb_shard_collection.orders.collection
+  min = "{\"region\": \"US\", \"_id\": {\"$minKey\": 1}}"
+  max = "{\"region\": \"US\", \"_id\": {\"$maxKey\": 1}}"
+  zone = mongodb_shard_zone.us.zone
+}
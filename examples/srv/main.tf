@@ -0,0 +1,29 @@
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    mongodb = {
+      source = "registry.terraform.io/Kaginari/mongodb"
+      version = "9.9.9"
+    }
+  }
+}
+## docker-compose -f docker/docker-compose-srv.yml up -d
+provider "mongodb" {
+  host = "cluster.mongo.test"
+  srv = true
+  srv_tls = false # the local mongo container has no TLS
+  dns_resolver = "127.0.0.1:1053" # the stub DNS server, not the internet
+  username = "root"
+  password = "root"
+  auth_database = "admin"
+}
+resource "mongodb_db_user" "user" {
+  auth_database = "admin"
+  name = "srv_user"
+  password = "srv_password"
+  role {
+    role = "readAnyDatabase"
+    db =   "admin"
+  }
+}
@@ -0,0 +1,34 @@
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    mongodb = {
+      source = "registry.terraform.io/Kaginari/mongodb"
+      version = "9.9.9"
+    }
+  }
+}
+## docker-compose -f docker/docker-compose-ssh.yml up -d
+provider "mongodb" {
+  host = "mongo"
+  port = "27017"
+  username = "root"
+  password = "root"
+  auth_database = "admin"
+  ssh_tunnel {
+    host = "localhost"
+    port = "2222"
+    user = "tunnel"
+    private_key = file("../../docker/docker-ssh/id_ed25519")
+    insecure_ignore_host_key = true # local test bastion only
+  }
+}
+resource "mongodb_db_user" "user" {
+  auth_database = "admin"
+  name = "tunnel_user"
+  password = "tunnel_password"
+  role {
+    role = "readAnyDatabase"
+    db =   "admin"
+  }
+}
@@ -2,16 +2,21 @@ package mongodb
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
-	"errors"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"golang.org/x/net/proxy"
+	"log"
+	"net"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,7 +33,70 @@ type ClientConfig struct {
 	Certificate        string
 	Direct             bool
 	Proxy              string
+	Srv                bool
+	SrvTLS             bool
+	DNSResolver        string
+	NoProxy            string
+	// zero values keep the driver defaults
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	SocketTimeout          time.Duration
+	HeartbeatInterval      time.Duration
+	MaxPoolSize            uint64
+	ReadPreference         string
+	ReadConcern            string
+	WriteConcern           WriteConcernConfig
+	// TLSServerName overrides the SNI name the server certificate is verified against.
+	TLSServerName string
+	// TLSAllowInvalidHostnames skips the hostname check while still verifying the chain.
+	TLSAllowInvalidHostnames bool
+	CAFile                   string
+	CADirectory              string
+	UseSystemCA              bool
+	ClientCertificateFile    string
+	ClientKeyFile            string
+	SSHTunnel                *SSHTunnelConfig
+	// CredentialsSource fills Username and Password when they are empty
+	CredentialsSource *CredentialsSource
+
+	// shared by the connection profiles so a single ssh session carries all the connections
+	dialer *sharedDialer
 }
+
+type sharedDialer struct {
+	once   sync.Once
+	dialer options.ContextDialer
+	err    error
+}
+type WriteConcernConfig struct {
+	// W is a number, "majority" or a tag set name
+	W        string
+	J        bool
+	WTimeout time.Duration
+}
+
+func (w WriteConcernConfig) writeConcern() *writeconcern.WriteConcern {
+	var opts []writeconcern.Option
+	if number, err := strconv.Atoi(w.W); err == nil {
+		opts = append(opts, writeconcern.W(number))
+	} else if w.W == "majority" {
+		opts = append(opts, writeconcern.WMajority())
+	} else if w.W != "" {
+		opts = append(opts, writeconcern.WTagSet(w.W))
+	}
+	if w.J {
+		opts = append(opts, writeconcern.J(true))
+	}
+	if w.WTimeout > 0 {
+		opts = append(opts, writeconcern.WTimeout(w.WTimeout))
+	}
+	if len(opts) == 0 {
+		// keep the server default
+		return nil
+	}
+	return writeconcern.New(opts...)
+}
+
 type DbUser struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
@@ -91,14 +159,14 @@ func addArgs(arguments string, newArg string) string {
 
 }
 
-func (c *ClientConfig) MongoClient() (*mongo.Client, error) {
+func (c *ClientConfig) MongoClient(ctx context.Context) (*mongo.Client, error) {
 
-	var verify = false
 	var arguments = ""
 
 	arguments = addArgs(arguments, "retrywrites="+strconv.FormatBool(c.RetryWrites))
 
-	if c.Ssl {
+	/* like mongodb+srv://, seedlist discovery turns TLS on */
+	if c.Ssl || (c.Srv && c.SrvTLS) {
 		arguments = addArgs(arguments, "ssl=true")
 	}
 
@@ -111,57 +179,103 @@ func (c *ClientConfig) MongoClient() (*mongo.Client, error) {
 	}
 
 	var uri = "mongodb://" + c.Host + ":" + c.Port + arguments
+	var authSource = c.DB
+
+	/*
+		seedlist discovery ( mongodb+srv:// ), the port is not used
+	*/
+	if c.Srv {
+		seedlist, txtOptions, err := c.resolveSeedlist(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for option := range txtOptions {
+			if strings.EqualFold(option, "replicaSet") && (c.ReplicaSet != "" || c.Direct) {
+				continue
+			}
+			/* the credential below replaces the authSource of the URI, an explicit auth_database wins */
+			if strings.EqualFold(option, "authSource") {
+				if authSource == "" {
+					authSource = txtOptions.Get(option)
+				}
+				continue
+			}
+			arguments = addArgs(arguments, option+"="+url.QueryEscape(txtOptions.Get(option)))
+		}
+		uri = "mongodb://" + strings.Join(seedlist, ",") + arguments
+	}
 
-	dialer, dialerErr := proxyDialer(c)
+	dialer, dialerErr := c.contextDialer()
 
 	if dialerErr != nil {
 		return nil, dialerErr
 	}
-	/*
-		@Since: v0.0.9
-		verify certificate
-	*/
-	if c.InsecureSkipVerify {
-		verify = true
+	if authSource == "" {
+		authSource = "admin"
 	}
-	/*
-		@Since: v0.0.7
-		add certificate support for documentDB
-	*/
-	if c.Certificate != "" {
-		tlsConfig, err := getTLSConfigWithAllServerCertificates([]byte(c.Certificate), verify)
+	credential := options.Credential{
+		AuthSource: authSource, Username: c.Username, Password: c.Password,
+	}
+	if c.CredentialsSource != nil {
+		resolved, err := c.CredentialsSource.get(ctx)
 		if err != nil {
 			return nil, err
 		}
-		mongoClient, err := mongo.NewClient(options.Client().ApplyURI(uri).SetAuth(options.Credential{
-			AuthSource: c.DB, Username: c.Username, Password: c.Password,
-		}).SetTLSConfig(tlsConfig).SetDialer(dialer))
-
-		return mongoClient, err
+		if credential.Username == "" {
+			credential.Username = resolved.Username
+		}
+		if credential.Password == "" {
+			credential.Password = resolved.Password
+		}
 	}
 
-	client, err := mongo.NewClient(options.Client().ApplyURI(uri).SetAuth(options.Credential{
-		AuthSource: c.DB, Username: c.Username, Password: c.Password,
-	}).SetDialer(dialer))
-	return client, err
-}
-
-func getTLSConfigWithAllServerCertificates(ca []byte, verify bool) (*tls.Config, error) {
-	/* As of version 1.2.1, the MongoDB Go Driver will only use the first CA server certificate found in sslcertificateauthorityfile.
-	   The code below addresses this limitation by manually appending all server certificates found in sslcertificateauthorityfile
-	   to a custom TLS configuration used during client creation. */
-
-	tlsConfig := new(tls.Config)
+	clientOptions := options.Client().ApplyURI(uri).SetDialer(dialer)
+	/* without a username the client does not authenticate, see the unauthenticated replica set seed */
+	if credential.Username != "" {
+		clientOptions.SetAuth(credential)
+	}
 
-	tlsConfig.InsecureSkipVerify = verify
-	tlsConfig.RootCAs = x509.NewCertPool()
-	ok := tlsConfig.RootCAs.AppendCertsFromPEM(ca)
+	if c.ConnectTimeout > 0 {
+		clientOptions.SetConnectTimeout(c.ConnectTimeout)
+	}
+	if c.ServerSelectionTimeout > 0 {
+		clientOptions.SetServerSelectionTimeout(c.ServerSelectionTimeout)
+	}
+	if c.SocketTimeout > 0 {
+		clientOptions.SetSocketTimeout(c.SocketTimeout)
+	}
+	if c.HeartbeatInterval > 0 {
+		clientOptions.SetHeartbeatInterval(c.HeartbeatInterval)
+	}
+	// max_pool_size defaults to 100, an explicit 0 lifts the limit
+	clientOptions.SetMaxPoolSize(c.MaxPoolSize)
+	if c.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(c.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		readPreference, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetReadPreference(readPreference)
+	}
+	if c.ReadConcern != "" {
+		clientOptions.SetReadConcern(readconcern.New(readconcern.Level(c.ReadConcern)))
+	}
+	if writeConcern := c.WriteConcern.writeConcern(); writeConcern != nil {
+		clientOptions.SetWriteConcern(writeConcern)
+	}
 
-	if !ok {
-		return tlsConfig, errors.New("Failed parsing pem file")
+	if c.customTLS() {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
 	}
 
-	return tlsConfig, nil
+	return mongo.NewClient(clientOptions)
 }
 
 func (privilege Privilege) String() string {
@@ -177,57 +291,79 @@ func (resource Resource) String() string {
 	return fmt.Sprintf(" { db : %s , collection : %s }", resource.Db, resource.Collection)
 }
 
-func createUser(client *mongo.Client, user DbUser, roles []Role, database string) error {
-	var result *mongo.SingleResult
+func createUser(ctx context.Context, client *mongo.Client, user DbUser, roles []Role, database string) error {
+	var command bson.D
 	if len(roles) != 0 {
-		result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "createUser", Value: user.Name},
-			{Key: "pwd", Value: user.Password}, {Key: "roles", Value: roles}})
+		command = bson.D{{Key: "createUser", Value: user.Name},
+			{Key: "pwd", Value: user.Password}, {Key: "roles", Value: roles}}
 	} else {
-		result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "createUser", Value: user.Name},
-			{Key: "pwd", Value: user.Password}, {Key: "roles", Value: []bson.M{}}})
+		command = bson.D{{Key: "createUser", Value: user.Name},
+			{Key: "pwd", Value: user.Password}, {Key: "roles", Value: []bson.M{}}}
 	}
 
-	if result.Err() != nil {
-		return result.Err()
-	}
-	return nil
+	command = withWriteConcern(client.Database(database), command)
+	return withRetry(ctx, "createUser "+user.Name, func(attempt int) error {
+		err := client.Database(database).RunCommand(ctx, command).Err()
+		if err != nil && attempt > 1 && hasErrorCode(err, errorCodeUserAlreadyExists) {
+			// the previous attempt may have been applied before its error came back
+			current, getErr := getUser(ctx, client, user.Name, database)
+			if getErr == nil && len(current.Users) == 1 && sameRoles(userRoles(current), roles, database) {
+				return nil
+			}
+		}
+		return err
+	})
+}
+
+func dropUser(ctx context.Context, client *mongo.Client, username string, database string) error {
+	return withRetry(ctx, "dropUser "+username, func(attempt int) error {
+		db := client.Database(database)
+		err := db.RunCommand(ctx, withWriteConcern(db, bson.D{{Key: "dropUser", Value: username}})).Err()
+		if err != nil && attempt > 1 && hasErrorCode(err, errorCodeUserNotFound) {
+			// dropped by the previous attempt
+			return nil
+		}
+		return err
+	})
 }
 
-func getUser(client *mongo.Client, username string, database string) (SingleResultGetUser, error) {
-	var result *mongo.SingleResult
-	result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "usersInfo", Value: bson.D{
-		{Key: "user", Value: username},
-		{Key: "db", Value: database},
-	},
-	}})
+func getUser(ctx context.Context, client *mongo.Client, username string, database string) (SingleResultGetUser, error) {
 	var decodedResult SingleResultGetUser
-	err := result.Decode(&decodedResult)
+	err := withRetry(ctx, "usersInfo "+username, func(int) error {
+		result := runReadCommand(ctx, client.Database(database), bson.D{{Key: "usersInfo", Value: bson.D{
+			{Key: "user", Value: username},
+			{Key: "db", Value: database},
+		},
+		}})
+		return result.Decode(&decodedResult)
+	})
 	if err != nil {
 		return decodedResult, err
 	}
 	return decodedResult, nil
 }
 
-func getRole(client *mongo.Client, roleName string, database string) (SingleResultGetRole, error) {
-	var result *mongo.SingleResult
-	result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "rolesInfo", Value: bson.D{
-		{Key: "role", Value: roleName},
-		{Key: "db", Value: database},
-	},
-	},
-		{Key: "showPrivileges", Value: true},
-	})
+func getRole(ctx context.Context, client *mongo.Client, roleName string, database string) (SingleResultGetRole, error) {
 	var decodedResult SingleResultGetRole
-	err := result.Decode(&decodedResult)
+	err := withRetry(ctx, "rolesInfo "+roleName, func(int) error {
+		result := runReadCommand(ctx, client.Database(database), bson.D{{Key: "rolesInfo", Value: bson.D{
+			{Key: "role", Value: roleName},
+			{Key: "db", Value: database},
+		},
+		},
+			{Key: "showPrivileges", Value: true},
+		})
+		return result.Decode(&decodedResult)
+	})
 	if err != nil {
 		return decodedResult, err
 	}
 	return decodedResult, nil
 }
 
-func createRole(client *mongo.Client, role string, roles []Role, privilege []PrivilegeDto, database string) error {
+func createRole(ctx context.Context, client *mongo.Client, role string, roles []Role, privilege []PrivilegeDto, database string) error {
 	var privileges []Privilege
-	var result *mongo.SingleResult
+	var command bson.D
 	for _, element := range privilege {
 		var prv Privilege
 		prv.Resource = Resource{
@@ -238,46 +374,260 @@ func createRole(client *mongo.Client, role string, roles []Role, privilege []Pri
 		privileges = append(privileges, prv)
 	}
 	if len(roles) != 0 && len(privileges) != 0 {
-		result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "createRole", Value: role},
-			{Key: "privileges", Value: privileges}, {Key: "roles", Value: roles}})
+		command = bson.D{{Key: "createRole", Value: role},
+			{Key: "privileges", Value: privileges}, {Key: "roles", Value: roles}}
 	} else if len(roles) == 0 && len(privileges) != 0 {
-		result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "createRole", Value: role},
-			{Key: "privileges", Value: privileges}, {Key: "roles", Value: []bson.M{}}})
+		command = bson.D{{Key: "createRole", Value: role},
+			{Key: "privileges", Value: privileges}, {Key: "roles", Value: []bson.M{}}}
 	} else if len(roles) != 0 && len(privileges) == 0 {
-		result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "createRole", Value: role},
-			{Key: "privileges", Value: []bson.M{}}, {Key: "roles", Value: roles}})
+		command = bson.D{{Key: "createRole", Value: role},
+			{Key: "privileges", Value: []bson.M{}}, {Key: "roles", Value: roles}}
 	} else {
-		result = client.Database(database).RunCommand(context.Background(), bson.D{{Key: "createRole", Value: role},
-			{Key: "privileges", Value: []bson.M{}}, {Key: "roles", Value: []bson.M{}}})
+		command = bson.D{{Key: "createRole", Value: role},
+			{Key: "privileges", Value: []bson.M{}}, {Key: "roles", Value: []bson.M{}}}
+	}
+
+	command = withWriteConcern(client.Database(database), command)
+	return withRetry(ctx, "createRole "+role, func(attempt int) error {
+		err := client.Database(database).RunCommand(ctx, command).Err()
+		if err != nil && attempt > 1 && hasErrorCode(err, errorCodeRoleAlreadyExists) {
+			// the previous attempt may have been applied before its error came back
+			current, getErr := getRole(ctx, client, role, database)
+			if getErr == nil && len(current.Roles) == 1 &&
+				sameRoles(inheritedRoles(current), roles, database) && samePrivileges(current, privileges) {
+				return nil
+			}
+		}
+		return err
+	})
+}
+
+func dropRole(ctx context.Context, client *mongo.Client, roleName string, database string) error {
+	return withRetry(ctx, "dropRole "+roleName, func(attempt int) error {
+		db := client.Database(database)
+		err := db.RunCommand(ctx, withWriteConcern(db, bson.D{{Key: "dropRole", Value: roleName}})).Err()
+		if err != nil && attempt > 1 && hasErrorCode(err, errorCodeRoleNotFound) {
+			// dropped by the previous attempt
+			return nil
+		}
+		return err
+	})
+}
+
+// withWriteConcern appends the configured write concern, RunCommand does not add it by itself.
+func withWriteConcern(db *mongo.Database, command bson.D) bson.D {
+	if db.WriteConcern() == nil {
+		return command
+	}
+	return append(command, bson.E{Key: "writeConcern", Value: db.WriteConcern()})
+}
+
+// readOptions sends read commands with the configured read preference, RunCommand defaults to the primary.
+func readOptions(db *mongo.Database) *options.RunCmdOptions {
+	return options.RunCmd().SetReadPreference(db.ReadPreference())
+}
+
+// runReadCommand sends command with the configured read preference and read concern, RunCommand adds neither by itself.
+// A server refusing a read concern on the command ( older versions do on usersInfo and rolesInfo ) gets it without.
+func runReadCommand(ctx context.Context, db *mongo.Database, command bson.D) *mongo.SingleResult {
+	if db.ReadConcern() == nil {
+		return db.RunCommand(ctx, command, readOptions(db))
+	}
+	result := db.RunCommand(ctx, append(command, bson.E{Key: "readConcern", Value: db.ReadConcern()}), readOptions(db))
+	if hasErrorCode(result.Err(), errorCodeInvalidOptions) {
+		log.Printf("[WARN] %s does not accept a read concern, sending it without : %s", command[0].Key, result.Err())
+		return db.RunCommand(ctx, command, readOptions(db))
+	}
+	return result
+}
+
+func userRoles(result SingleResultGetUser) []Role {
+	roles := make([]Role, len(result.Users[0].Roles))
+	for i, role := range result.Users[0].Roles {
+		roles[i] = Role(role)
+	}
+	return roles
+}
+
+func inheritedRoles(result SingleResultGetRole) []Role {
+	roles := make([]Role, len(result.Roles[0].InheritedRoles))
+	for i, role := range result.Roles[0].InheritedRoles {
+		roles[i] = Role(role)
+	}
+	return roles
+}
+
+// sameRoles compares roles regardless of their order, a role without db belongs to database.
+func sameRoles(current []Role, expected []Role, database string) bool {
+	key := func(role Role) string {
+		if role.Db == "" {
+			return role.Role + "@" + database
+		}
+		return role.Role + "@" + role.Db
+	}
+	var currentKeys, expectedKeys []string
+	for _, role := range current {
+		currentKeys = append(currentKeys, key(role))
 	}
+	for _, role := range expected {
+		expectedKeys = append(expectedKeys, key(role))
+	}
+	return sameStrings(currentKeys, expectedKeys)
+}
 
-	if result.Err() != nil {
-		return result.Err()
+func samePrivileges(current SingleResultGetRole, expected []Privilege) bool {
+	key := func(db string, collection string, actions []string) string {
+		sorted := append([]string(nil), actions...)
+		sort.Strings(sorted)
+		return db + "." + collection + ":" + strings.Join(sorted, ",")
+	}
+	var currentKeys, expectedKeys []string
+	for _, privilege := range current.Roles[0].Privileges {
+		currentKeys = append(currentKeys, key(privilege.Resource.Db, privilege.Resource.Collection, privilege.Actions))
+	}
+	for _, privilege := range expected {
+		expectedKeys = append(expectedKeys, key(privilege.Resource.Db, privilege.Resource.Collection, privilege.Actions))
 	}
-	return nil
+	return sameStrings(currentKeys, expectedKeys)
 }
 
-func MongoClientInit(conf *MongoDatabaseConfiguration) (*mongo.Client, error) {
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
 
-	client, err := conf.Config.MongoClient()
+// MongoClientInit returns the client of the connection profile, "" being the provider block itself.
+// The client is created on first use and cached afterwards.
+func MongoClientInit(ctx context.Context, conf *MongoDatabaseConfiguration, connection string) (*mongo.Client, error) {
+	conf, err := conf.profile(connection)
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), conf.MaxConnLifetime*time.Second)
+
+	conf.clientLock.Lock()
+	defer conf.clientLock.Unlock()
+	var resolved credentials
+	if source := conf.Config.CredentialsSource; source != nil && (conf.client == nil || source.Expired()) {
+		resolved, err = source.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// past the credentials TTL a new client is only built when the credentials changed
+		if conf.client != nil && resolved != conf.clientCredentials {
+			conf.retireClient(conf.client)
+			conf.client = nil
+		}
+	}
+	if conf.client != nil {
+		conf.useClient(ctx, conf.client)
+		return conf.client, nil
+	}
+
+	// the operation using the client, the connection is also bounded by MaxConnLifetime
+	operation := ctx
+	ctx, cancel := context.WithTimeout(ctx, conf.MaxConnLifetime)
 	defer cancel()
+	client, err := conf.Config.MongoClient(ctx)
+	if err != nil {
+		return nil, err
+	}
 	err = client.Connect(ctx)
 	if err != nil {
 		return nil, err
 	}
 	err = client.Ping(ctx, nil)
 	if err != nil {
+		_ = client.Disconnect(context.Background())
 		return nil, err
 	}
+	conf.client = client
+	conf.clientCredentials = resolved
+	conf.useClient(operation, client)
 	return client, nil
 }
 
+// useClient counts ctx as a user of client until ctx is done, every resource operation bounds its ctx by a timeout.
+// Must be called with clientLock held.
+func (conf *MongoDatabaseConfiguration) useClient(ctx context.Context, client *mongo.Client) {
+	if ctx.Done() == nil {
+		return
+	}
+	if conf.clientUsers == nil {
+		conf.clientUsers = map[*mongo.Client]int{}
+	}
+	conf.clientUsers[client]++
+	go func() {
+		<-ctx.Done()
+		conf.clientLock.Lock()
+		defer conf.clientLock.Unlock()
+		conf.clientUsers[client]--
+		if conf.clientUsers[client] > 0 {
+			return
+		}
+		delete(conf.clientUsers, client)
+		if conf.retired[client] {
+			delete(conf.retired, client)
+			go disconnectClient(client)
+		}
+	}()
+}
+
+// retireClient disconnects client once the operations still using it are done.
+// Must be called with clientLock held.
+func (conf *MongoDatabaseConfiguration) retireClient(client *mongo.Client) {
+	if conf.clientUsers[client] == 0 {
+		go disconnectClient(client)
+		return
+	}
+	if conf.retired == nil {
+		conf.retired = map[*mongo.Client]bool{}
+	}
+	conf.retired[client] = true
+}
+
+func disconnectClient(client *mongo.Client) {
+	if err := client.Disconnect(context.Background()); err != nil {
+		log.Printf("[WARN] failed disconnecting a client built with expired credentials : %s", err)
+	}
+}
+
+func (c *ClientConfig) contextDialer() (options.ContextDialer, error) {
+	if c.dialer == nil {
+		c.dialer = &sharedDialer{}
+	}
+	c.dialer.once.Do(func() {
+		c.dialer.dialer, c.dialer.err = proxyDialer(c)
+		if c.dialer.err != nil || c.SSHTunnel == nil {
+			return
+		}
+		// the proxy, if any, is used to reach the ssh host
+		c.dialer.dialer, c.dialer.err = sshTunnelDialer(c.SSHTunnel, c.dialer.dialer)
+	})
+	return c.dialer.dialer, c.dialer.err
+}
+
+// directDialer resolves the hosts with dns_resolver when it is set.
+func (c *ClientConfig) directDialer() *net.Dialer {
+	if c.DNSResolver == "" {
+		return &net.Dialer{}
+	}
+	return &net.Dialer{Resolver: c.resolver()}
+}
+
 func proxyDialer(c *ClientConfig) (options.ContextDialer, error) {
-	proxyFromEnv := proxy.FromEnvironment().(options.ContextDialer)
+	direct := c.directDialer()
+	proxyFromEnv := proxy.FromEnvironmentUsing(direct).(options.ContextDialer)
 	proxyFromProvider := c.Proxy
 
 	if len(proxyFromProvider) > 0 {
@@ -285,11 +635,23 @@ func proxyDialer(c *ClientConfig) (options.ContextDialer, error) {
 		if err != nil {
 			return nil, err
 		}
-		proxyDialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+		proxyDialer, err := proxy.FromURL(proxyURL, direct)
 		if err != nil {
 			return nil, err
 		}
 
+		/*
+			hosts matching no_proxy are dialed directly
+		*/
+		if strings.TrimSpace(c.NoProxy) == "*" {
+			return direct, nil
+		}
+		if c.NoProxy != "" {
+			perHost := proxy.NewPerHost(proxyDialer, direct)
+			perHost.AddFromString(c.NoProxy)
+			return perHost, nil
+		}
+
 		return proxyDialer.(options.ContextDialer), nil
 	}
 
@@ -0,0 +1,143 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsSource fills the username and password left empty in the configuration
+// from a credentials file and/or the JSON output of a command. The values are cached for TTL.
+type CredentialsSource struct {
+	File    string
+	Profile string
+	Command string
+	// TTL of the resolved values, 0 keeps them for the life of the provider
+	TTL time.Duration
+
+	lock    sync.Mutex
+	cached  *credentials
+	expires time.Time
+}
+
+type credentials struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+}
+
+type credentialsFile struct {
+	credentials `yaml:",inline"`
+	Profiles    map[string]credentials `yaml:"profiles"`
+}
+
+// copy returns a source with the same settings and its own cache, so each client
+// notices its own expiry.
+func (s *CredentialsSource) copy() *CredentialsSource {
+	return &CredentialsSource{File: s.File, Profile: s.Profile, Command: s.Command, TTL: s.TTL}
+}
+
+// get returns the cached credentials, resolving them again once expired.
+func (s *CredentialsSource) get(ctx context.Context) (credentials, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.cached != nil && !s.expired() {
+		return *s.cached, nil
+	}
+
+	var resolved credentials
+	if s.File != "" {
+		fromFile, err := readCredentialsFile(s.File, s.Profile)
+		if err != nil {
+			return credentials{}, err
+		}
+		resolved = fromFile
+	}
+	// the command takes precedence over the file
+	if s.Command != "" {
+		fromCommand, err := runCredentialsCommand(ctx, s.Command)
+		if err != nil {
+			return credentials{}, err
+		}
+		if fromCommand.Username != "" {
+			resolved.Username = fromCommand.Username
+		}
+		if fromCommand.Password != "" {
+			resolved.Password = fromCommand.Password
+		}
+	}
+
+	s.cached = &resolved
+	s.expires = time.Now().Add(s.TTL)
+	return resolved, nil
+}
+
+func (s *CredentialsSource) expired() bool {
+	return s.TTL > 0 && time.Now().After(s.expires)
+}
+
+// Expired reports whether the credentials in use have to be resolved again.
+func (s *CredentialsSource) Expired() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cached == nil || s.expired()
+}
+
+func readCredentialsFile(path string, profile string) (credentials, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return credentials{}, fmt.Errorf("could not expand %s : %s", path, err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return credentials{}, fmt.Errorf("failed reading credentials_file : %s", err)
+	}
+
+	// YAML is a superset of JSON, both are read the same way
+	var file credentialsFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return credentials{}, fmt.Errorf("failed parsing credentials_file %s : %s", path, err)
+	}
+	if len(file.Profiles) == 0 {
+		return file.credentials, nil
+	}
+	found, ok := file.Profiles[profile]
+	if !ok {
+		return credentials{}, fmt.Errorf("no profile %s in credentials_file %s", profile, path)
+	}
+	return found, nil
+}
+
+func runCredentialsCommand(ctx context.Context, command string) (credentials, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// stdout is never printed, it holds the secrets
+		return credentials{}, fmt.Errorf("credentials_command failed : %s : %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var resolved credentials
+	if err := json.Unmarshal(stdout.Bytes(), &resolved); err != nil {
+		return credentials{}, fmt.Errorf("credentials_command did not print a JSON object with username and password : %s", err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,230 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func dataSourceQuery() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceQueryRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"collection": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"filter": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The query filter in Extended JSON",
+			},
+			"projection": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The projection in Extended JSON",
+			},
+			"sort": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The sort specification in Extended JSON",
+			},
+			"limit": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          100,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(1)),
+			},
+			"max_result_size": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          1048576,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(1)),
+				Description:      "The maximum size in bytes of the BSON results, a larger result is an error",
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The documents in relaxed Extended JSON",
+			},
+			"first": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The first document flattened, by dotted field path",
+			},
+		},
+	}
+}
+
+func dataSourceQueryRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var collection = data.Get("collection").(string)
+	var maxResultSize = data.Get("max_result_size").(int)
+
+	filter, err := queryDocument(data.Get("filter").(string), "filter")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	findOptions := options.Find().SetLimit(int64(data.Get("limit").(int)))
+	if projection := data.Get("projection").(string); projection != "" {
+		document, err := queryDocument(projection, "projection")
+		if err != nil {
+			return diag.Errorf("%s", err)
+		}
+		findOptions.SetProjection(document)
+	}
+	if sort := data.Get("sort").(string); sort != "" {
+		document, err := queryDocument(sort, "sort")
+		if err != nil {
+			return diag.Errorf("%s", err)
+		}
+		findOptions.SetSort(document)
+	}
+
+	var documents []bson.Raw
+	err = withRetry(ctx, "find "+database+"."+collection, func(int) error {
+		cursor, err := client.Database(database).Collection(collection).Find(ctx, filter, findOptions)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+		documents = nil
+		var size int
+		for cursor.Next(ctx) {
+			size += len(cursor.Current)
+			if size > maxResultSize {
+				return fmt.Errorf("the result is larger than max_result_size ( %d bytes ), narrow the filter or lower the limit", maxResultSize)
+			}
+			documents = append(documents, append(bson.Raw{}, cursor.Current...))
+		}
+		return cursor.Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not query %s.%s : %s ", database, collection, err)
+	}
+
+	var results = make([]string, len(documents))
+	for index, document := range documents {
+		results[index], err = formatExtendedJSON(document)
+		if err != nil {
+			return diag.Errorf("Error encoding document : %s ", err)
+		}
+	}
+	var first = map[string]string{}
+	if len(results) > 0 {
+		if err := flattenExtendedJSON(results[0], first); err != nil {
+			return diag.Errorf("Error flattening document : %s ", err)
+		}
+	}
+
+	if err := data.Set("results", results); err != nil {
+		return diag.Errorf("error setting results : %s ", err)
+	}
+	if err := data.Set("first", first); err != nil {
+		return diag.Errorf("error setting first : %s ", err)
+	}
+	id := sha256.Sum256([]byte(strings.Join([]string{database, collection, data.Get("filter").(string), data.Get("projection").(string), data.Get("sort").(string)}, "\n")))
+	data.SetId(hex.EncodeToString(id[:]))
+	return nil
+}
+
+func queryDocument(document string, name string) (bson.Raw, error) {
+	value, err := parseExtendedJSON(document)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding %s : %s ", name, err)
+	}
+	if value.Type != bsontype.EmbeddedDocument {
+		return nil, fmt.Errorf("%s must be a JSON object", name)
+	}
+	return value.Document(), nil
+}
+
+// flattenExtendedJSON flattens a relaxed Extended JSON document into dotted paths, array elements are indexed.
+// Extended JSON wrappers such as {"$oid": ...} or {"$date": ...} are reduced to their value.
+func flattenExtendedJSON(document string, flattened map[string]string) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return flattenValue("", value, flattened)
+}
+
+func flattenValue(path string, value interface{}, flattened map[string]string) error {
+	var prefix string
+	if path != "" {
+		prefix = path + "."
+	}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if len(typed) == 1 {
+			for key, wrapped := range typed {
+				if text, ok := wrapped.(string); ok && strings.HasPrefix(key, "$") {
+					flattened[path] = text
+					return nil
+				}
+			}
+		}
+		for key, element := range typed {
+			if err := flattenValue(prefix+key, element, flattened); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for index, element := range typed {
+			if err := flattenValue(prefix+strconv.Itoa(index), element, flattened); err != nil {
+				return err
+			}
+		}
+	case string:
+		flattened[path] = typed
+	case nil:
+		flattened[path] = ""
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return err
+		}
+		flattened[path] = string(encoded)
+	}
+	return nil
+}
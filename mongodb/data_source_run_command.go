@@ -0,0 +1,120 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"strings"
+	"time"
+)
+
+// defaultRunCommandAllowList are read-only diagnostic commands, overridden by the run_command_allow_list provider argument.
+var defaultRunCommandAllowList = []string{
+	"buildInfo", "collStats", "connectionStatus", "dbStats", "getCmdLineOpts", "getDefaultRWConcern", "getLog",
+	"getParameter", "hello", "hostInfo", "isMaster", "listCommands", "listDatabases", "listShards", "ping",
+	"replSetGetConfig", "replSetGetStatus", "rolesInfo", "serverStatus",
+}
+
+func dataSourceRunCommand() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRunCommandRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "admin",
+			},
+			"command": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The command document in Extended JSON, the command name is its first field",
+			},
+			"result": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The reply in canonical Extended JSON",
+			},
+			"result_relaxed": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The reply in relaxed Extended JSON",
+			},
+		},
+	}
+}
+
+func dataSourceRunCommandRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	var database = data.Get("database").(string)
+	command, err := queryDocument(data.Get("command").(string), "command")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	elements, err := command.Elements()
+	if err != nil || len(elements) == 0 {
+		return diag.Errorf("the command document is empty")
+	}
+	var name = elements[0].Key()
+	if !containsFold(config.RunCommandAllowList, name) {
+		return diag.Errorf("the command %s is not in the run_command_allow_list of the provider", name)
+	}
+
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var reply bson.Raw
+	err = withRetry(ctx, name, func(int) error {
+		var err error
+		reply, err = client.Database(database).RunCommand(ctx, command, readOptions(client.Database(database))).DecodeBytes()
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not run %s : %s ", name, err)
+	}
+
+	/* the cluster time fields change on every call */
+	replyElements, err := reply.Elements()
+	if err != nil {
+		return diag.Errorf("Error decoding the reply : %s ", err)
+	}
+	var result bson.D
+	for _, element := range replyElements {
+		if element.Key() != "$clusterTime" && element.Key() != "operationTime" {
+			result = append(result, bson.E{Key: element.Key(), Value: element.Value()})
+		}
+	}
+	canonical, err := formatCanonicalExtendedJSON(result)
+	if err != nil {
+		return diag.Errorf("Error encoding the reply : %s ", err)
+	}
+	relaxed, err := formatExtendedJSON(result)
+	if err != nil {
+		return diag.Errorf("Error encoding the reply : %s ", err)
+	}
+	if err := data.Set("result", canonical); err != nil {
+		return diag.Errorf("error setting result : %s ", err)
+	}
+	if err := data.Set("result_relaxed", relaxed); err != nil {
+		return diag.Errorf("error setting result_relaxed : %s ", err)
+	}
+	id := sha256.Sum256([]byte(strings.Join([]string{data.Get("connection").(string), database, data.Get("command").(string)}, "\n")))
+	data.SetId(hex.EncodeToString(id[:]))
+	return nil
+}
@@ -0,0 +1,191 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"strings"
+	"time"
+)
+
+type SingleResultServerStatus struct {
+	StorageEngine struct {
+		Name string `bson:"name"`
+	} `bson:"storageEngine"`
+}
+
+func dataSourceServerInfo() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServerInfoRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"version_array": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+			"git_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"max_wire_version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"feature_compatibility_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Empty on DocumentDB or Cosmos DB",
+			},
+			"storage_engine": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Empty on mongos",
+			},
+			"topology_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "standalone, replset or sharded",
+			},
+			"set_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"primary": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"members": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The data bearing members, hidden members are not listed",
+			},
+			"arbiters": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"flavor": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "mongodb, documentdb, cosmosdb or unknown",
+			},
+		},
+	}
+}
+
+func dataSourceServerInfoRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	var connection = data.Get("connection").(string)
+	client, connectionError := MongoClientInit(ctx, config, connection)
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	profile, err := config.profile(connection)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	build, err := buildInfo(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not run buildInfo : %s ", err)
+	}
+	hello, err := isMaster(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not run isMaster : %s ", err)
+	}
+	var topologyType = "standalone"
+	if hello.Msg == "isdbgrid" {
+		topologyType = "sharded"
+	} else if hello.SetName != "" {
+		topologyType = "replset"
+	}
+
+	var storageEngine string
+	if topologyType != "sharded" {
+		var status SingleResultServerStatus
+		err := withRetry(ctx, "serverStatus", func(int) error {
+			command := bson.D{{Key: "serverStatus", Value: 1}, {Key: "repl", Value: 0}, {Key: "metrics", Value: 0}, {Key: "locks", Value: 0}}
+			return client.Database("admin").RunCommand(ctx, command).Decode(&status)
+		})
+		if err != nil {
+			return diag.Errorf("Could not run serverStatus : %s ", err)
+		}
+		storageEngine = status.StorageEngine.Name
+	}
+	fcv, err := featureCompatibilityVersion(ctx, client)
+	if err != nil && (ctx.Err() != nil || isRetryableError(err)) {
+		return diag.Errorf("Could not read the featureCompatibilityVersion : %s ", err)
+	}
+	if err != nil {
+		log.Printf("[WARN] could not read the featureCompatibilityVersion : %s", err)
+	}
+	var flavor = serverFlavor(err, profile.Config.Host)
+
+	versionArray := make([]int, len(build.VersionArray))
+	for index, part := range build.VersionArray {
+		versionArray[index] = int(part)
+	}
+	var values = map[string]interface{}{
+		"version":                       build.Version,
+		"version_array":                 versionArray,
+		"git_version":                   build.GitVersion,
+		"max_wire_version":              int(hello.MaxWireVersion),
+		"feature_compatibility_version": fcv,
+		"storage_engine":                storageEngine,
+		"topology_type":                 topologyType,
+		"set_name":                      hello.SetName,
+		"primary":                       hello.Primary,
+		"members":                       append(append([]string{}, hello.Hosts...), hello.Passives...),
+		"arbiters":                      hello.Arbiters,
+		"flavor":                        flavor,
+	}
+	for key, value := range values {
+		if err := data.Set(key, value); err != nil {
+			return diag.Errorf("error setting %s : %s ", key, err)
+		}
+	}
+	if connection == "" {
+		data.SetId(profile.Config.Host)
+	} else {
+		data.SetId(connection)
+	}
+	return nil
+}
+
+// serverFlavor tells MongoDB from the compatible cloud services by the featureCompatibilityVersion reply : every mongod
+// and mongos reports it, the services reject it and claim a MongoDB version in buildInfo. The host name then names
+// the service, a user not allowed to read it is taken for MongoDB and any other rejection reports unknown.
+func serverFlavor(fcvErr error, host string) string {
+	if fcvErr == nil {
+		return "mongodb"
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	switch {
+	case strings.HasSuffix(host, ".docdb.amazonaws.com") || strings.HasSuffix(host, ".docdb-elastic.amazonaws.com"):
+		return "documentdb"
+	case strings.HasSuffix(host, ".cosmos.azure.com") || strings.HasSuffix(host, ".documents.azure.com"):
+		return "cosmosdb"
+	case hasErrorCode(fcvErr, errorCodeUnauthorized):
+		return "mongodb"
+	default:
+		return "unknown"
+	}
+}
@@ -1,10 +1,16 @@
 package mongodb
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"strings"
 )
 
 func validateDiagFunc(validateFunc func(interface{}, string) ([]string, []error)) schema.SchemaValidateDiagFunc {
@@ -26,3 +32,168 @@ func validateDiagFunc(validateFunc func(interface{}, string) ([]string, []error)
 		return diags
 	}
 }
+
+// importStateWithConnection imports "<connection>:<id>" through a connection profile, a bare id uses the provider block.
+func importStateWithConnection(ctx context.Context, data *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
+	if parts := strings.SplitN(data.Id(), ":", 2); len(parts) == 2 {
+		if err := data.Set("connection", parts[0]); err != nil {
+			return nil, err
+		}
+		data.SetId(parts[1])
+	}
+	return []*schema.ResourceData{data}, nil
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys, so field order does not matter when comparing.
+func canonicalJSON(document []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	var canonical bytes.Buffer
+	encoder := json.NewEncoder(&canonical)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(canonical.String(), "\n"), nil
+}
+
+// suppressEquivalentJSON ignores the formatting and field order differences of two JSON documents.
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return old == new
+	}
+	canonicalOld, err := canonicalJSON([]byte(old))
+	if err != nil {
+		return false
+	}
+	canonicalNew, err := canonicalJSON([]byte(new))
+	if err != nil {
+		return false
+	}
+	return canonicalOld == canonicalNew
+}
+
+// parseExtendedJSON decodes a relaxed or canonical Extended JSON value, documents and arrays alike, keeping the BSON types.
+func parseExtendedJSON(value string) (bson.RawValue, error) {
+	if !json.Valid([]byte(value)) {
+		return bson.RawValue{}, fmt.Errorf("invalid JSON : %s", value)
+	}
+	var wrapper struct {
+		Value bson.RawValue `bson:"value"`
+	}
+	if err := bson.UnmarshalExtJSON([]byte(`{"value":`+value+`}`), false, &wrapper); err != nil {
+		return bson.RawValue{}, err
+	}
+	return wrapper.Value, nil
+}
+
+// formatExtendedJSON encodes a BSON value as relaxed Extended JSON with sorted keys.
+func formatExtendedJSON(value interface{}) (string, error) {
+	return marshalExtendedJSON(value, false)
+}
+
+// formatCanonicalExtendedJSON encodes a BSON value as canonical Extended JSON with sorted keys, which keeps the
+// numeric and date types that relaxed Extended JSON folds together.
+func formatCanonicalExtendedJSON(value interface{}) (string, error) {
+	return marshalExtendedJSON(value, true)
+}
+
+func marshalExtendedJSON(value interface{}, canonical bool) (string, error) {
+	document, err := bson.MarshalExtJSON(bson.D{{Key: "value", Value: value}}, canonical, false)
+	if err != nil {
+		return "", err
+	}
+	var wrapper struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(document, &wrapper); err != nil {
+		return "", err
+	}
+	return canonicalJSON(wrapper.Value)
+}
+
+// orderedExtendedJSON encodes a BSON value as relaxed Extended JSON keeping the field order, which matters in pipeline
+// stages, shard keys and chunk bounds.
+func orderedExtendedJSON(value interface{}) (string, error) {
+	document, err := bson.MarshalExtJSON(bson.D{{Key: "value", Value: value}}, false, false)
+	if err != nil {
+		return "", err
+	}
+	var wrapper struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(document, &wrapper); err != nil {
+		return "", err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, wrapper.Value); err != nil {
+		return "", err
+	}
+	return compact.String(), nil
+}
+
+// equivalentDocuments compares two documents field by field in order, numbers of different types are equal when their
+// values are, as the shell writes doubles where the provider writes integers.
+func equivalentDocuments(a bson.Raw, b bson.Raw) bool {
+	aElements, aErr := a.Elements()
+	bElements, bErr := b.Elements()
+	if aErr != nil || bErr != nil || len(aElements) != len(bElements) {
+		return false
+	}
+	for index := range aElements {
+		if aElements[index].Key() != bElements[index].Key() || !equivalentValues(aElements[index].Value(), bElements[index].Value()) {
+			return false
+		}
+	}
+	return true
+}
+
+func equivalentValues(a bson.RawValue, b bson.RawValue) bool {
+	aNumber, aIsNumber := rawNumber(a)
+	bNumber, bIsNumber := rawNumber(b)
+	switch {
+	case aIsNumber || bIsNumber:
+		return aIsNumber && bIsNumber && aNumber == bNumber
+	case a.Type == bsontype.EmbeddedDocument && b.Type == bsontype.EmbeddedDocument:
+		return equivalentDocuments(a.Document(), b.Document())
+	case a.Type == bsontype.Array && b.Type == bsontype.Array:
+		// an array is a document keyed by the indexes
+		return equivalentDocuments(bson.Raw(a.Array()), bson.Raw(b.Array()))
+	default:
+		return a.Equal(b)
+	}
+}
+
+func rawNumber(value bson.RawValue) (float64, bool) {
+	switch value.Type {
+	case bsontype.Int32:
+		return float64(value.Int32()), true
+	case bsontype.Int64:
+		return float64(value.Int64()), true
+	case bsontype.Double:
+		return value.Double(), true
+	default:
+		return 0, false
+	}
+}
+
+// suppressEquivalentOrderedExtendedJSON ignores the formatting and number type differences of two Extended JSON values
+// but keeps the field order, which has a meaning in pipeline stages such as $sort.
+func suppressEquivalentOrderedExtendedJSON(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return old == new
+	}
+	parsedOld, err := parseExtendedJSON(old)
+	if err != nil {
+		return false
+	}
+	parsedNew, err := parseExtendedJSON(new)
+	if err != nil {
+		return false
+	}
+	return equivalentValues(parsedOld, parsedNew)
+}
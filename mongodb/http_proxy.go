@@ -0,0 +1,120 @@
+package mongodb
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/net/proxy"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func init() {
+	// let proxy.FromURL build HTTP CONNECT dialers next to the socks5 ones
+	proxy.RegisterDialerType("http", newHTTPConnectDialer)
+	proxy.RegisterDialerType("https", newHTTPConnectDialer)
+}
+
+// httpConnectDialer opens connections through an HTTP(S) proxy with the CONNECT method.
+type httpConnectDialer struct {
+	proxyURL *url.URL
+	forward  proxy.Dialer
+}
+
+func newHTTPConnectDialer(proxyURL *url.URL, forward proxy.Dialer) (proxy.Dialer, error) {
+	if proxyURL.Port() == "" {
+		return nil, fmt.Errorf("the proxy URL %s has no port", proxyURL.Redacted())
+	}
+	return &httpConnectDialer{proxyURL: proxyURL, forward: forward}, nil
+}
+
+func (d *httpConnectDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d *httpConnectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialProxy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach proxy %s : %s", d.proxyURL.Host, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	connected, err := d.connect(conn, address)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return connected, nil
+}
+
+func (d *httpConnectDialer) dialProxy(ctx context.Context) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if forward, ok := d.forward.(proxy.ContextDialer); ok {
+		conn, err = forward.DialContext(ctx, "tcp", d.proxyURL.Host)
+	} else {
+		conn, err = d.forward.Dial("tcp", d.proxyURL.Host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.proxyURL.Scheme != "https" {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: d.proxyURL.Hostname()})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func (d *httpConnectDialer) connect(conn net.Conn, address string) (net.Conn, error) {
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := d.proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := request.Write(conn); err != nil {
+		return nil, fmt.Errorf("proxy CONNECT to %s failed : %s", address, err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, fmt.Errorf("proxy CONNECT to %s failed : %s", address, err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy CONNECT to %s failed : %s", address, response.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		// the server spoke first, keep what was read along with the response
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
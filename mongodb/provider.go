@@ -2,10 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/mongo"
 	"regexp"
+	"sync"
 	"time"
 )
 
@@ -24,30 +27,105 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("MONGO_PORT", "27017"),
 				Description: "The mongodb server port",
 			},
+			"srv": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "discover the servers from the SRV and TXT records of host ( mongodb+srv:// )",
+			},
+			"srv_tls": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "turn TLS on with srv like a mongodb+srv:// connection string, false for a deployment without TLS",
+			},
+			"dns_resolver": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("MONGO_DNS_RESOLVER", ""),
+				ValidateDiagFunc: validateDiagFunc(validation.StringMatch(regexp.MustCompile("^.+:\\d+$"), "The dns resolver must be a host:port address.")),
+				Description:      "DNS server ( host:port ) used for the SRV and TXT lookups instead of the system resolver",
+			},
 			"certificate": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("MONGODB_CERT", ""),
 				Description: "PEM-encoded content of Mongodb host CA certificate",
 			},
+			"ca_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MONGODB_CA_FILE", ""),
+				Description: "Path to a PEM file containing one or more CA certificates",
+			},
+			"ca_directory": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Path to a directory of .pem, .crt or .cer CA certificates",
+			},
+			"use_system_ca": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "append the provided CA certificates to the system pool instead of replacing it",
+			},
+			"client_certificate_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Path to the PEM-encoded client certificate presented to the server",
+			},
+			"client_key_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Path to the PEM-encoded client key, defaults to client_certificate_file",
+			},
 
 			"username": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("MONGO_USR", nil),
 				Description: "The mongodb user",
 			},
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("MONGO_PWD", nil),
 				Description: "The mongodb password",
 			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MONGO_CREDENTIALS_FILE", ""),
+				Description: "Path to a YAML or JSON file holding the username and password",
+			},
+			"credentials_profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "default",
+				Description: "The profile read from credentials_file when it declares profiles",
+			},
+			"credentials_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Shell command printing a JSON object with the username and password",
+			},
+			"credentials_ttl": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          300,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Seconds the credentials read from credentials_file or credentials_command are cached, 0 means forever",
+			},
 			"auth_database": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "admin",
-				Description: "The mongodb auth database",
+				Default:     "",
+				Description: "The mongodb auth database, defaults to the authSource of the SRV TXT record or admin",
 			},
 			"replica_set": {
 				Type:        schema.TypeString,
@@ -61,6 +139,18 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "ignore hostname verification",
 			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The server name (SNI) the server certificate is verified against",
+			},
+			"tls_allow_invalid_hostnames": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "skip only the hostname check, the certificate chain is still verified",
+			},
 			"ssl": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -86,14 +176,274 @@ func Provider() *schema.Provider {
 					"ALL_PROXY",
 					"all_proxy",
 				}, nil),
-				ValidateDiagFunc: validateDiagFunc(validation.StringMatch(regexp.MustCompile("^socks5h?://.*:\\d+$"), "The proxy URL is not a valid socks url.")),
+				ValidateDiagFunc: validateDiagFunc(validation.StringMatch(regexp.MustCompile("^(socks5h?|https?)://.*:\\d+$"), "The proxy URL is not a valid socks or http url.")),
+				Description:      "socks5, socks5h, http or https proxy URL, credentials can be set in the URL",
+			},
+			"no_proxy": {
+				Type:     schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{
+					"NO_PROXY",
+					"no_proxy",
+				}, ""),
+				Description: "Comma-separated hosts, domains and CIDRs reached without the proxy",
+			},
+			"connect_timeout": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Seconds to wait for a connection to be established",
+			},
+			"server_selection_timeout": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          30,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Seconds to wait for a suitable server to be available",
+			},
+			"socket_timeout": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Seconds to wait for a socket read or write, 0 means no timeout",
+			},
+			"heartbeat_interval": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Seconds between two server monitoring checks",
+			},
+			"max_pool_size": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          100,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Maximum number of connections per server, 0 means no limit",
+			},
+			"read_preference": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "primary",
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{
+					"primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest",
+				}, false)),
+				Description: "The read preference of the reads made by the provider",
+			},
+			"read_concern": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "majority",
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{
+					"local", "available", "majority", "linearizable", "snapshot",
+				}, false)),
+				Description: "The read concern level of the reads made by the provider",
+			},
+			"write_concern": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The write concern of the writes made by the provider, defaults to majority",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"w": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "majority",
+							Description: "number of members, majority or a tag set name",
+						},
+						"j": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "wait for the write to be written to the on-disk journal",
+						},
+						"wtimeout": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          0,
+							ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(0)),
+							Description:      "milliseconds to wait for the write concern, 0 means no limit",
+						},
+					},
+				},
+			},
+			"run_command_allow_list": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The commands the mongodb_run_command data source may run, defaults to read-only commands",
+			},
+			"connection": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Named connection profiles, selected by the connection argument of the resources",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name the resources use to select the profile",
+						},
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The mongodb server address",
+						},
+						"port": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "27017",
+							Description: "The mongodb server port",
+						},
+						"srv": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "discover the servers from the SRV and TXT records of host ( mongodb+srv:// )",
+						},
+						"srv_tls": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "turn TLS on with srv like a mongodb+srv:// connection string, false for a deployment without TLS",
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The mongodb user, set with password, defaults to the provider credentials",
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Default:     "",
+							Description: "The mongodb password, set with username, defaults to the provider credentials",
+						},
+						"auth_database": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The mongodb auth database, defaults to the provider one",
+						},
+						"replica_set": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The mongodb replica set",
+						},
+						"ssl": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "ssl activation",
+						},
+						"direct": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "enforces a direct connection instead of discovery",
+						},
+						"certificate": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "PEM-encoded content of Mongodb host CA certificate, defaults to the provider one",
+						},
+						"tls_server_name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The server name (SNI) the server certificate is verified against",
+						},
+					},
+				},
+			},
+			"ssh_tunnel": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Tunnel every mongodb connection through an ssh bastion",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The ssh bastion address",
+						},
+						"port": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "22",
+							Description: "The ssh bastion port",
+						},
+						"user": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The ssh user",
+						},
+						"private_key": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							DefaultFunc: schema.EnvDefaultFunc("MONGO_SSH_PRIVATE_KEY", ""),
+							Description: "PEM-encoded content of the ssh private key",
+						},
+						"private_key_passphrase": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Default:     "",
+							Description: "The passphrase of the ssh private key",
+						},
+						"use_agent": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "authenticate with the keys of the ssh agent listening on SSH_AUTH_SOCK",
+						},
+						"known_hosts_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Path to the known_hosts file used to verify the bastion, defaults to ~/.ssh/known_hosts",
+						},
+						"insecure_ignore_host_key": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "do not verify the bastion host key",
+						},
+					},
+				},
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"mongodb_db_user": resourceDatabaseUser(),
-			"mongodb_db_role": resourceDatabaseRole(),
+			"mongodb_db_user":                       resourceDatabaseUser(),
+			"mongodb_db_role":                       resourceDatabaseRole(),
+			"mongodb_server_parameter":              resourceServerParameter(),
+			"mongodb_profiling_level":               resourceProfilingLevel(),
+			"mongodb_view":                          resourceView(),
+			"mongodb_materialized_view":             resourceMaterializedView(),
+			"mongodb_document":                      resourceDocument(),
+			"mongodb_documents":                     resourceDocuments(),
+			"mongodb_feature_compatibility_version": resourceFeatureCompatibilityVersion(),
+			"mongodb_replica_set_config":            resourceReplicaSetConfig(),
+			"mongodb_replica_set_initiate":          resourceReplicaSetInitiate(),
+			"mongodb_database_sharding":             resourceDatabaseSharding(),
+			"mongodb_shard_collection":              resourceShardCollection(),
+			"mongodb_shard_zone":                    resourceShardZone(),
+			"mongodb_zone_key_range":                resourceZoneKeyRange(),
+			"mongodb_shard":                         resourceShard(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"mongodb_query":       dataSourceQuery(),
+			"mongodb_run_command": dataSourceRunCommand(),
+			"mongodb_server_info": dataSourceServerInfo(),
 		},
-		DataSourcesMap:       map[string]*schema.Resource{},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
@@ -101,29 +451,195 @@ func Provider() *schema.Provider {
 type MongoDatabaseConfiguration struct {
 	Config          *ClientConfig
 	MaxConnLifetime time.Duration
+	// RunCommandAllowList are the commands the mongodb_run_command data source may run
+	RunCommandAllowList []string
+	// Connections are the connection profiles, selected by the connection argument of the resources
+	Connections map[string]*MongoDatabaseConfiguration
+
+	clientLock sync.Mutex
+	client     *mongo.Client
+	// clientCredentials are the resolved credentials client was built with
+	clientCredentials credentials
+	// clientUsers counts the operations still running with a client, retired clients are disconnected once unused
+	clientUsers map[*mongo.Client]int
+	retired     map[*mongo.Client]bool
+}
+
+func (c *MongoDatabaseConfiguration) profile(name string) (*MongoDatabaseConfiguration, error) {
+	if name == "" {
+		return c, nil
+	}
+	profile, ok := c.Connections[name]
+	if !ok {
+		return nil, fmt.Errorf("no connection named %s in the provider configuration", name)
+	}
+	return profile, nil
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	clientConfig := ClientConfig{
-		Host:               d.Get("host").(string),
-		Port:               d.Get("port").(string),
-		Username:           d.Get("username").(string),
-		Password:           d.Get("password").(string),
-		DB:                 d.Get("auth_database").(string),
-		Ssl:                d.Get("ssl").(bool),
-		ReplicaSet:         d.Get("replica_set").(string),
-		Certificate:        d.Get("certificate").(string),
-		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
-		Direct:             d.Get("direct").(bool),
-		RetryWrites:        d.Get("retrywrites").(bool),
-		Proxy:              d.Get("proxy").(string),
+		Host:                     d.Get("host").(string),
+		Port:                     d.Get("port").(string),
+		Username:                 d.Get("username").(string),
+		Password:                 d.Get("password").(string),
+		DB:                       d.Get("auth_database").(string),
+		Ssl:                      d.Get("ssl").(bool),
+		ReplicaSet:               d.Get("replica_set").(string),
+		Certificate:              d.Get("certificate").(string),
+		InsecureSkipVerify:       d.Get("insecure_skip_verify").(bool),
+		TLSServerName:            d.Get("tls_server_name").(string),
+		TLSAllowInvalidHostnames: d.Get("tls_allow_invalid_hostnames").(bool),
+		CAFile:                   d.Get("ca_file").(string),
+		CADirectory:              d.Get("ca_directory").(string),
+		UseSystemCA:              d.Get("use_system_ca").(bool),
+		ClientCertificateFile:    d.Get("client_certificate_file").(string),
+		ClientKeyFile:            d.Get("client_key_file").(string),
+		Direct:                   d.Get("direct").(bool),
+		RetryWrites:              d.Get("retrywrites").(bool),
+		Proxy:                    d.Get("proxy").(string),
+		Srv:                      d.Get("srv").(bool),
+		SrvTLS:                   d.Get("srv_tls").(bool),
+		DNSResolver:              d.Get("dns_resolver").(string),
+		NoProxy:                  d.Get("no_proxy").(string),
+		ConnectTimeout:           time.Duration(d.Get("connect_timeout").(int)) * time.Second,
+		ServerSelectionTimeout:   time.Duration(d.Get("server_selection_timeout").(int)) * time.Second,
+		SocketTimeout:            time.Duration(d.Get("socket_timeout").(int)) * time.Second,
+		HeartbeatInterval:        time.Duration(d.Get("heartbeat_interval").(int)) * time.Second,
+		MaxPoolSize:              uint64(d.Get("max_pool_size").(int)),
+		ReadPreference:           d.Get("read_preference").(string),
+		ReadConcern:              d.Get("read_concern").(string),
+		WriteConcern:             WriteConcernConfig{W: "majority"},
+		dialer:                   &sharedDialer{},
+	}
+
+	if concerns := d.Get("write_concern").([]interface{}); len(concerns) > 0 && concerns[0] != nil {
+		concern := concerns[0].(map[string]interface{})
+		clientConfig.WriteConcern = WriteConcernConfig{
+			W:        concern["w"].(string),
+			J:        concern["j"].(bool),
+			WTimeout: time.Duration(concern["wtimeout"].(int)) * time.Millisecond,
+		}
+	}
+
+	if tunnels := d.Get("ssh_tunnel").([]interface{}); len(tunnels) > 0 && tunnels[0] != nil {
+		tunnel := tunnels[0].(map[string]interface{})
+		clientConfig.SSHTunnel = &SSHTunnelConfig{
+			Host:                  tunnel["host"].(string),
+			Port:                  tunnel["port"].(string),
+			User:                  tunnel["user"].(string),
+			PrivateKey:            tunnel["private_key"].(string),
+			PrivateKeyPassphrase:  tunnel["private_key_passphrase"].(string),
+			UseAgent:              tunnel["use_agent"].(bool),
+			KnownHostsFile:        tunnel["known_hosts_file"].(string),
+			InsecureIgnoreHostKey: tunnel["insecure_ignore_host_key"].(bool),
+		}
+	}
+
+	if file, command := d.Get("credentials_file").(string), d.Get("credentials_command").(string); file != "" || command != "" {
+		clientConfig.CredentialsSource = &CredentialsSource{
+			File:    file,
+			Profile: d.Get("credentials_profile").(string),
+			Command: command,
+			TTL:     time.Duration(d.Get("credentials_ttl").(int)) * time.Second,
+		}
+		resolved, err := clientConfig.CredentialsSource.get(ctx)
+		if err != nil {
+			return nil, append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Error resolving the provider credentials",
+				Detail:   err.Error(),
+			})
+		}
+		if clientConfig.Username == "" && resolved.Username == "" {
+			return nil, diag.Errorf("no username found in credentials_file or credentials_command")
+		}
+	} else if clientConfig.Username == "" {
+		return nil, diag.Errorf("username is required, set it in the provider block, MONGO_USR, credentials_file or credentials_command")
+	}
+
+	if clientConfig.customTLS() {
+		if _, err := clientConfig.tlsConfig(); err != nil {
+			return nil, append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Error loading TLS certificates",
+				Detail:   err.Error(),
+			})
+		}
+	}
+
+	connections := map[string]*MongoDatabaseConfiguration{}
+	for _, raw := range d.Get("connection").([]interface{}) {
+		connection := raw.(map[string]interface{})
+		name := connection["name"].(string)
+		if _, exists := connections[name]; exists {
+			return nil, diag.Errorf("connection %s is declared more than once", name)
+		}
+		if (connection["username"].(string) == "") != (connection["password"].(string) == "") {
+			return nil, diag.Errorf("connection %s sets username or password without the other, set both or none", name)
+		}
+		profile := connectionClientConfig(&clientConfig, connection)
+		if profile.customTLS() {
+			if _, err := profile.tlsConfig(); err != nil {
+				return nil, append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("Error loading TLS certificates of connection %s", name),
+					Detail:   err.Error(),
+				})
+			}
+		}
+		connections[name] = &MongoDatabaseConfiguration{
+			Config:          profile,
+			MaxConnLifetime: profile.ConnectTimeout + profile.ServerSelectionTimeout,
+		}
+	}
+
+	var runCommandAllowList = defaultRunCommandAllowList
+	if allowList, ok := d.GetOk("run_command_allow_list"); ok {
+		runCommandAllowList = nil
+		for _, command := range allowList.([]interface{}) {
+			runCommandAllowList = append(runCommandAllowList, command.(string))
+		}
 	}
 
+	// connecting and pinging the server are both bounded by MaxConnLifetime
 	return &MongoDatabaseConfiguration{
-		Config:          &clientConfig,
-		MaxConnLifetime: 10,
+		Config:              &clientConfig,
+		MaxConnLifetime:     clientConfig.ConnectTimeout + clientConfig.ServerSelectionTimeout,
+		RunCommandAllowList: runCommandAllowList,
+		Connections:         connections,
 	}, diags
 
 }
+
+// connectionClientConfig copies the provider configuration and overrides it with a connection block.
+// The server settings ( host, port, srv, ssl, direct, replica_set, tls_server_name ) always come from the block,
+// empty credentials, auth_database and certificate keep the provider value and the transport settings
+// ( proxy, ssh_tunnel, timeouts ... ) are shared.
+func connectionClientConfig(base *ClientConfig, connection map[string]interface{}) *ClientConfig {
+	profile := *base
+	profile.Host = connection["host"].(string)
+	profile.Port = connection["port"].(string)
+	profile.Srv = connection["srv"].(bool)
+	profile.SrvTLS = connection["srv_tls"].(bool)
+	profile.Ssl = connection["ssl"].(bool)
+	profile.Direct = connection["direct"].(bool)
+	profile.ReplicaSet = connection["replica_set"].(string)
+	profile.TLSServerName = connection["tls_server_name"].(string)
+	/* username and password come together, they replace the provider credentials and their source */
+	if username := connection["username"].(string); username != "" {
+		profile.Username = username
+		profile.Password = connection["password"].(string)
+		profile.CredentialsSource = nil
+	} else if base.CredentialsSource != nil {
+		profile.CredentialsSource = base.CredentialsSource.copy()
+	}
+	if authDatabase := connection["auth_database"].(string); authDatabase != "" {
+		profile.DB = authDatabase
+	}
+	if certificate := connection["certificate"].(string); certificate != "" {
+		profile.Certificate = certificate
+	}
+	return &profile
+}
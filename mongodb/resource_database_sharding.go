@@ -0,0 +1,137 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"time"
+)
+
+type ConfigDatabase struct {
+	Id          string `bson:"_id"`
+	Primary     string `bson:"primary"`
+	Partitioned *bool  `bson:"partitioned,omitempty"`
+}
+
+func resourceDatabaseSharding() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDatabaseShardingCreate,
+		ReadContext:   resourceDatabaseShardingRead,
+		DeleteContext: resourceDatabaseShardingDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"primary_shard": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The shard holding the unsharded collections, chosen by the cluster when omitted",
+			},
+		},
+	}
+}
+
+func resourceDatabaseShardingCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	command := bson.D{{Key: "enableSharding", Value: database}}
+	if primary := data.Get("primary_shard").(string); primary != "" {
+		command = append(command, bson.E{Key: "primaryShard", Value: primary})
+	}
+	err := withRetry(ctx, "enableSharding "+database, func(int) error {
+		err := client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Err()
+		/* servers before 4.0 refuse to enable sharding twice */
+		if err != nil && hasErrorCode(err, errorCodeAlreadyInitialized) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not enable sharding on %s : %s ", database, err)
+	}
+	/* an existing database keeps its primary shard, which would be replaced on every plan */
+	if primary := data.Get("primary_shard").(string); primary != "" {
+		document, found, err := findConfigDocument(ctx, client, "databases", bson.D{{Key: "_id", Value: database}})
+		if err != nil {
+			return diag.Errorf("Could not read config.databases : %s ", err)
+		}
+		var result ConfigDatabase
+		if found {
+			if err := bson.Unmarshal(document, &result); err != nil {
+				return diag.Errorf("Error decoding config.databases : %s ", err)
+			}
+		}
+		if found && result.Primary != primary {
+			return diag.Errorf("%s already exists with the primary shard %s, move it to %s with movePrimary or set primary_shard to %s", database, result.Primary, primary, result.Primary)
+		}
+	}
+	data.SetId(database)
+	return resourceDatabaseShardingRead(ctx, data, i)
+}
+
+func resourceDatabaseShardingRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Id()
+	document, found, err := findConfigDocument(ctx, client, "databases", bson.D{{Key: "_id", Value: database}})
+	if err != nil {
+		return diag.Errorf("Could not read config.databases : %s ", err)
+	}
+	var result ConfigDatabase
+	if found {
+		if err := bson.Unmarshal(document, &result); err != nil {
+			return diag.Errorf("Error decoding config.databases : %s ", err)
+		}
+	}
+	/* partitioned is gone from 6.0, where every database accepts sharded collections */
+	if !found || (result.Partitioned != nil && !*result.Partitioned) {
+		log.Printf("[WARN] sharding is not enabled on %s, removing from state", database)
+		data.SetId("")
+		return nil
+	}
+	if err := data.Set("database", database); err != nil {
+		return diag.Errorf("error setting database : %s ", err)
+	}
+	if err := data.Set("primary_shard", result.Primary); err != nil {
+		return diag.Errorf("error setting primary_shard : %s ", err)
+	}
+	return nil
+}
+
+/* sharding cannot be disabled on a database, destroy only forgets it */
+func resourceDatabaseShardingDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	log.Printf("[INFO] sharding left enabled on %s", data.Id())
+	return nil
+}
@@ -7,8 +7,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/mitchellh/mapstructure"
-	"go.mongodb.org/mongo-driver/bson"
 	"strings"
+	"time"
 )
 
 func resourceDatabaseRole() *schema.Resource {
@@ -18,9 +18,22 @@ func resourceDatabaseRole() *schema.Resource {
 		UpdateContext: resourceDatabaseRoleUpdate,
 		DeleteContext: resourceDatabaseRoleDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
 			"database": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -78,8 +91,10 @@ func resourceDatabaseRole() *schema.Resource {
 }
 
 func resourceDatabaseRoleCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -101,7 +116,7 @@ func resourceDatabaseRoleCreate(ctx context.Context, data *schema.ResourceData,
 	}
 
 
-	err := createRole(client, role, roleList, privileges, database)
+	err := createRole(ctx, client, role, roleList, privileges, database)
 
 	if err != nil {
 		return diag.Errorf("Could not create the role : %s ", err)
@@ -113,8 +128,10 @@ func resourceDatabaseRoleCreate(ctx context.Context, data *schema.ResourceData,
 }
 
 func resourceDatabaseRoleDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -125,19 +142,20 @@ func resourceDatabaseRoleDelete(ctx context.Context, data *schema.ResourceData,
 		return diag.Errorf("%s", err)
 	}
 
-	db := client.Database(database)
-	result := db.RunCommand(context.Background(), bson.D{{Key: "dropRole", Value: roleName}})
+	err = dropRole(ctx, client, roleName, database)
 
-	if result.Err() != nil {
-		return diag.Errorf("%s",result.Err())
+	if err != nil {
+		return diag.Errorf("%s", err)
 	}
 
 	return nil
 }
 
 func resourceDatabaseRoleUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -149,11 +167,10 @@ func resourceDatabaseRoleUpdate(ctx context.Context, data *schema.ResourceData,
 		return diag.Errorf("%s",err)
 	}
 
-	db := client.Database(database)
-	result := db.RunCommand(context.Background(), bson.D{{Key: "dropRole", Value: roleName}})
+	err = dropRole(ctx, client, roleName, database)
 
-	if result.Err() != nil {
-		return diag.Errorf("%s", result.Err())
+	if err != nil {
+		return diag.Errorf("%s", err)
 	}
 
 	var roleList []Role
@@ -171,7 +188,7 @@ func resourceDatabaseRoleUpdate(ctx context.Context, data *schema.ResourceData,
 		return diag.Errorf("Error decoding map : %s ", privMapErr)
 	}
 
-	err2 := createRole(client, role, roleList, privileges, database)
+	err2 := createRole(ctx, client, role, roleList, privileges, database)
 
 	if err2 != nil {
 		return diag.Errorf("Could not create the role  :  %s ", err)
@@ -185,9 +202,11 @@ func resourceDatabaseRoleUpdate(ctx context.Context, data *schema.ResourceData,
 }
 
 func resourceDatabaseRoleRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
 	var diags diag.Diagnostics
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -196,7 +215,7 @@ func resourceDatabaseRoleRead(ctx context.Context, data *schema.ResourceData, i
 	if err != nil {
 		return diag.Errorf("%s",err)
 	}
-	result , decodeError := getRole(client,roleName,database)
+	result , decodeError := getRole(ctx, client, roleName, database)
 	if decodeError != nil {
 		return diag.Errorf("Error decoding role : %s ", err)
 	}
@@ -7,8 +7,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/mitchellh/mapstructure"
-	"go.mongodb.org/mongo-driver/bson"
 	"strings"
+	"time"
 )
 
 func resourceDatabaseUser() *schema.Resource {
@@ -18,9 +18,22 @@ func resourceDatabaseUser() *schema.Resource {
 		UpdateContext: resourceDatabaseUserUpdate,
 		DeleteContext: resourceDatabaseUserDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
 			"auth_database": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -57,8 +70,10 @@ func resourceDatabaseUser() *schema.Resource {
 
 
 func resourceDatabaseUserDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -74,19 +89,19 @@ func resourceDatabaseUserDelete(ctx context.Context, data *schema.ResourceData,
 	splitId := strings.Split(string(id), ".")
 	userName := splitId[1]
 
-	adminDB := client.Database(database)
-
-	result := adminDB.RunCommand(context.Background(), bson.D{{Key: "dropUser", Value: userName}})
-	if result.Err() != nil {
-		return diag.Errorf("%s",result.Err())
+	err := dropUser(ctx, client, userName, database)
+	if err != nil {
+		return diag.Errorf("%s", err)
 	}
 
 	return nil
 }
 
 func resourceDatabaseUserUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -100,11 +115,9 @@ func resourceDatabaseUserUpdate(ctx context.Context, data *schema.ResourceData,
 	var database = data.Get("auth_database").(string)
 	var userPassword = data.Get("password").(string)
 	
-	adminDB := client.Database(database)
-
-	result := adminDB.RunCommand(context.Background(), bson.D{{Key: "dropUser", Value: userName}})
-	if result.Err() != nil {
-		return diag.Errorf("%s",result.Err())
+	err := dropUser(ctx, client, userName, database)
+	if err != nil {
+		return diag.Errorf("%s", err)
 	}
 	var roleList []Role
 	var user = DbUser{
@@ -116,7 +129,7 @@ func resourceDatabaseUserUpdate(ctx context.Context, data *schema.ResourceData,
 	if roleMapErr != nil {
 		return diag.Errorf("Error decoding map : %s ", roleMapErr)
 	}
-	err2 := createUser(client,user,roleList,database)
+	err2 := createUser(ctx, client, user, roleList, database)
 	if err2 != nil {
 		return diag.Errorf("Could not create the user : %s ", err2)
 	}
@@ -128,8 +141,10 @@ func resourceDatabaseUserUpdate(ctx context.Context, data *schema.ResourceData,
 }
 
 func resourceDatabaseUserRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -138,7 +153,7 @@ func resourceDatabaseUserRead(ctx context.Context, data *schema.ResourceData, i
 	if err != nil {
 		return diag.Errorf("%s",err)
 	}
-	result , decodeError := getUser(client,username,database)
+	result , decodeError := getUser(ctx, client, username, database)
 	if decodeError != nil {
 		return diag.Errorf("Error decoding user : %s ", err)
 	}
@@ -170,8 +185,10 @@ func resourceDatabaseUserRead(ctx context.Context, data *schema.ResourceData, i
 }
 
 func resourceDatabaseUserCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
 	var config = i.(*MongoDatabaseConfiguration)
-	client , connectionError := MongoClientInit(config)
+	client , connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
 	if connectionError != nil {
 		return diag.Errorf("Error connecting to database : %s ", connectionError)
 	}
@@ -188,7 +205,7 @@ func resourceDatabaseUserCreate(ctx context.Context, data *schema.ResourceData,
 	if roleMapErr != nil {
 		return diag.Errorf("Error decoding map : %s ", roleMapErr)
 	}
-	err := createUser(client,user,roleList,database)
+	err := createUser(ctx, client, user, roleList, database)
 	if err != nil {
 		return diag.Errorf("Could not create the user : %s ", err)
 	}
@@ -0,0 +1,300 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
+	"time"
+)
+
+func resourceDocument() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDocumentCreate,
+		ReadContext:   resourceDocumentRead,
+		UpdateContext: resourceDocumentUpdate,
+		DeleteContext: resourceDocumentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		/* the _id identifies the document, a new _id is a new document */
+		CustomizeDiff: customdiff.ForceNewIfChange("document", func(ctx context.Context, old, new, meta interface{}) bool {
+			oldId, oldErr := documentIdJSON(old.(string))
+			newId, newErr := documentIdJSON(new.(string))
+			return oldErr == nil && newErr == nil && oldId != newId
+		}),
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"collection": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"document": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				DiffSuppressFunc: suppressEquivalentDocument,
+				Description:      "The document in Extended JSON, including its _id",
+			},
+		},
+	}
+}
+
+func resourceDocumentCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var collection = data.Get("collection").(string)
+	document, err := parseDocument(data.Get("document").(string))
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	if err := upsertDocument(ctx, client.Database(database).Collection(collection), document); err != nil {
+		return diag.Errorf("Could not upsert the document : %s ", err)
+	}
+	id, err := formatCanonicalExtendedJSON(document.Lookup("_id"))
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	data.SetId(resourceDocumentId(database, collection, id))
+	return resourceDocumentRead(ctx, data, i)
+}
+
+func resourceDocumentRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	database, collection, id, err := resourceDocumentParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	filter, err := parseExtendedJSON(id)
+	if err != nil {
+		return diag.Errorf("Error decoding _id : %s ", err)
+	}
+
+	var current bson.Raw
+	var found = true
+	err = withRetry(ctx, "find "+database+"."+collection, func(int) error {
+		err := client.Database(database).Collection(collection).FindOne(ctx, bson.D{{Key: "_id", Value: filter}}).Decode(&current)
+		if err == mongo.ErrNoDocuments {
+			found = false
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not read the document : %s ", err)
+	}
+	if !found {
+		data.SetId("")
+		return nil
+	}
+
+	/* the configured document is kept while it holds the same BSON, otherwise the server one is reported,
+	   in canonical form when only the types differ so the drift stays visible */
+	state, err := documentState(data.Get("document").(string), current)
+	if err != nil {
+		return diag.Errorf("Error decoding document : %s ", err)
+	}
+	if err := data.Set("document", state); err != nil {
+		return diag.Errorf("error setting document : %s ", err)
+	}
+	if err := data.Set("database", database); err != nil {
+		return diag.Errorf("error setting database : %s ", err)
+	}
+	if err := data.Set("collection", collection); err != nil {
+		return diag.Errorf("error setting collection : %s ", err)
+	}
+	return nil
+}
+
+func resourceDocumentUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	database, collection, _, err := resourceDocumentParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	document, err := parseDocument(data.Get("document").(string))
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	if err := upsertDocument(ctx, client.Database(database).Collection(collection), document); err != nil {
+		return diag.Errorf("Could not upsert the document : %s ", err)
+	}
+	return resourceDocumentRead(ctx, data, i)
+}
+
+func resourceDocumentDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	database, collection, id, err := resourceDocumentParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	filter, err := parseExtendedJSON(id)
+	if err != nil {
+		return diag.Errorf("Error decoding _id : %s ", err)
+	}
+	err = withRetry(ctx, "delete "+database+"."+collection, func(int) error {
+		_, err := client.Database(database).Collection(collection).DeleteOne(ctx, bson.D{{Key: "_id", Value: filter}})
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not delete the document : %s ", err)
+	}
+	return nil
+}
+
+/* the _id index follows the default collation of the collection, _id lookups keep it */
+func upsertDocument(ctx context.Context, collection *mongo.Collection, document bson.Raw) error {
+	filter := bson.D{{Key: "_id", Value: document.Lookup("_id")}}
+	return withRetry(ctx, "upsert "+collection.Database().Name()+"."+collection.Name(), func(int) error {
+		_, err := collection.ReplaceOne(ctx, filter, document, options.Replace().SetUpsert(true))
+		return err
+	})
+}
+
+// parseDocument decodes an Extended JSON document, which must have an _id.
+func parseDocument(document string) (bson.Raw, error) {
+	value, err := parseExtendedJSON(document)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding document : %s ", err)
+	}
+	if value.Type != bsontype.EmbeddedDocument {
+		return nil, fmt.Errorf("the document must be a JSON object")
+	}
+	if _, err := value.Document().LookupErr("_id"); err != nil {
+		return nil, fmt.Errorf("the document must have an _id")
+	}
+	return value.Document(), nil
+}
+
+func documentIdJSON(document string) (string, error) {
+	parsed, err := parseDocument(document)
+	if err != nil {
+		return "", err
+	}
+	return formatCanonicalExtendedJSON(parsed.Lookup("_id"))
+}
+
+// documentState returns the value of the document argument matching current, the document found on the server.
+func documentState(configured string, current bson.Raw) (string, error) {
+	currentCanonical, err := formatCanonicalExtendedJSON(current)
+	if err != nil {
+		return "", err
+	}
+	if configured != "" {
+		if configuredValue, err := parseExtendedJSON(configured); err == nil {
+			if configuredCanonical, err := formatCanonicalExtendedJSON(configuredValue); err == nil {
+				if configuredCanonical == currentCanonical {
+					return configured, nil
+				}
+				relaxed, err := formatExtendedJSON(current)
+				if err != nil {
+					return "", err
+				}
+				if configuredRelaxed, err := formatExtendedJSON(configuredValue); err == nil && configuredRelaxed == relaxed {
+					return currentCanonical, nil
+				}
+				return relaxed, nil
+			}
+		}
+	}
+	return formatExtendedJSON(current)
+}
+
+// suppressEquivalentDocument compares documents in canonical Extended JSON, so an int32 and an int64 differ.
+func suppressEquivalentDocument(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return old == new
+	}
+	oldValue, err := parseExtendedJSON(old)
+	if err != nil {
+		return false
+	}
+	newValue, err := parseExtendedJSON(new)
+	if err != nil {
+		return false
+	}
+	canonicalOld, err := formatCanonicalExtendedJSON(oldValue)
+	if err != nil {
+		return false
+	}
+	canonicalNew, err := formatCanonicalExtendedJSON(newValue)
+	if err != nil {
+		return false
+	}
+	return canonicalOld == canonicalNew
+}
+
+// resourceDocumentId is the base64 encoded "database.collection" and the base64 encoded canonical Extended JSON _id.
+func resourceDocumentId(database string, collection string, id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(database+"."+collection)) + "." + base64.StdEncoding.EncodeToString([]byte(id))
+}
+
+func resourceDocumentParseId(id string) (string, string, string, error) {
+	encoded := strings.SplitN(id, ".", 2)
+	if len(encoded) != 2 {
+		return "", "", "", fmt.Errorf("unexpected format of ID (%s), expected base64(database.collection).base64(_id)", id)
+	}
+	namespace, errEncoding := base64.StdEncoding.DecodeString(encoded[0])
+	if errEncoding != nil {
+		return "", "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	documentId, errEncoding := base64.StdEncoding.DecodeString(encoded[1])
+	if errEncoding != nil {
+		return "", "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(namespace), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || len(documentId) == 0 {
+		return "", "", "", fmt.Errorf("unexpected format of ID (%s), expected base64(database.collection).base64(_id)", id)
+	}
+	return parts[0], parts[1], string(documentId), nil
+}
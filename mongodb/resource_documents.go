@@ -0,0 +1,407 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+func resourceDocuments() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDocumentsCreate,
+		ReadContext:   resourceDocumentsRead,
+		UpdateContext: resourceDocumentsUpdate,
+		DeleteContext: resourceDocumentsDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"collection": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "_id",
+				Description: "The field identifying a document, dotted for an embedded field",
+			},
+			"documents": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				/* only a hash of the documents is kept in the state */
+				StateFunc: func(value interface{}) string {
+					hash, err := documentsContentHash(value.(string))
+					if err != nil {
+						return ""
+					}
+					return hash
+				},
+				Description: "A JSON array of Extended JSON documents",
+			},
+			"document_hashes": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The SHA-256 of each document, by canonical Extended JSON key",
+			},
+		},
+	}
+}
+
+func resourceDocumentsCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var database = data.Get("database").(string)
+	var collection = data.Get("collection").(string)
+	if diags := reconcileDocuments(ctx, data, i, map[string]interface{}{}); diags != nil {
+		return diags
+	}
+	data.SetId(base64.StdEncoding.EncodeToString([]byte(database + "." + collection)))
+	return resourceDocumentsRead(ctx, data, i)
+}
+
+func resourceDocumentsRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var collection = data.Get("collection").(string)
+	var key = data.Get("key").(string)
+
+	var keys bson.A
+	for encoded := range data.Get("document_hashes").(map[string]interface{}) {
+		value, err := parseExtendedJSON(encoded)
+		if err != nil {
+			return diag.Errorf("Error decoding key %s : %s ", encoded, err)
+		}
+		keys = append(keys, value)
+	}
+
+	/* the hashes of the documents found on the server, a missing or changed document changes the documents hash */
+	var hashes = map[string]string{}
+	if len(keys) > 0 {
+		collation, err := keyCollation(ctx, client.Database(database).Collection(collection), key)
+		if err != nil {
+			return diag.Errorf("Could not read the collection options : %s ", err)
+		}
+		err = withRetry(ctx, "find "+database+"."+collection, func(int) error {
+			cursor, err := client.Database(database).Collection(collection).Find(ctx, bson.D{{Key: key, Value: bson.D{{Key: "$in", Value: keys}}}}, options.Find().SetCollation(collation))
+			if err != nil {
+				return err
+			}
+			defer cursor.Close(ctx)
+			hashes = map[string]string{}
+			for cursor.Next(ctx) {
+				encodedKey, hash, err := documentHash(cursor.Current, key)
+				if err != nil {
+					return err
+				}
+				hashes[encodedKey] = hash
+			}
+			return cursor.Err()
+		})
+		if err != nil {
+			return diag.Errorf("Could not read the documents : %s ", err)
+		}
+	}
+
+	if sameHashes(hashes, data.Get("document_hashes").(map[string]interface{})) {
+		return nil
+	}
+	/* the documents no longer hash to the configured ones, clearing the hash plans an update */
+	log.Printf("[WARN] documents of %s.%s changed outside of Terraform", database, collection)
+	if err := data.Set("document_hashes", hashes); err != nil {
+		return diag.Errorf("error setting document_hashes : %s ", err)
+	}
+	if err := data.Set("documents", ""); err != nil {
+		return diag.Errorf("error setting documents : %s ", err)
+	}
+	return nil
+}
+
+func resourceDocumentsUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	if diags := reconcileDocuments(ctx, data, i, data.Get("document_hashes").(map[string]interface{})); diags != nil {
+		return diags
+	}
+	return resourceDocumentsRead(ctx, data, i)
+}
+
+func resourceDocumentsDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var collection = data.Get("collection").(string)
+	var key = data.Get("key").(string)
+	collation, err := keyCollation(ctx, client.Database(database).Collection(collection), key)
+	if err != nil {
+		return diag.Errorf("Could not read the collection options : %s ", err)
+	}
+
+	var models []mongo.WriteModel
+	for encoded := range data.Get("document_hashes").(map[string]interface{}) {
+		value, err := parseExtendedJSON(encoded)
+		if err != nil {
+			return diag.Errorf("Error decoding key %s : %s ", encoded, err)
+		}
+		models = append(models, mongo.NewDeleteOneModel().SetFilter(bson.D{{Key: key, Value: value}}).SetCollation(collation))
+	}
+	if err := bulkWriteDocuments(ctx, client.Database(database).Collection(collection), models); err != nil {
+		return diag.Errorf("Could not delete the documents : %s ", err)
+	}
+	return nil
+}
+
+// reconcileDocuments upserts the documents whose hash differs from previous and deletes the keys of previous no longer
+// configured, with a single bulkWrite.
+func reconcileDocuments(ctx context.Context, data *schema.ResourceData, i interface{}, previous map[string]interface{}) diag.Diagnostics {
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var collection = data.Get("collection").(string)
+	var key = data.Get("key").(string)
+	hashes, documents, err := documentsHashes(data.Get("documents").(string), key)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	collation, err := keyCollation(ctx, client.Database(database).Collection(collection), key)
+	if err != nil {
+		return diag.Errorf("Could not read the collection options : %s ", err)
+	}
+
+	var models []mongo.WriteModel
+	var upserts, deletes int
+	for _, encoded := range sortedKeys(hashes) {
+		if previous[encoded] == hashes[encoded] {
+			continue
+		}
+		document := documents[encoded]
+		filter := bson.D{{Key: key, Value: document.Lookup(strings.Split(key, ".")...)}}
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(document).SetUpsert(true).SetCollation(collation))
+		upserts++
+	}
+	for encoded := range previous {
+		if _, ok := hashes[encoded]; ok {
+			continue
+		}
+		value, err := parseExtendedJSON(encoded)
+		if err != nil {
+			return diag.Errorf("Error decoding key %s : %s ", encoded, err)
+		}
+		models = append(models, mongo.NewDeleteOneModel().SetFilter(bson.D{{Key: key, Value: value}}).SetCollation(collation))
+		deletes++
+	}
+	log.Printf("[INFO] %s.%s : %d upserts, %d deletes", database, collection, upserts, deletes)
+	if err := bulkWriteDocuments(ctx, client.Database(database).Collection(collection), models); err != nil {
+		return diag.Errorf("Could not write the documents : %s ", err)
+	}
+	if err := data.Set("document_hashes", hashes); err != nil {
+		return diag.Errorf("error setting document_hashes : %s ", err)
+	}
+	return nil
+}
+
+// keyCollation returns the simple collation when the collection has another default collation and key is not _id,
+// so "A" and "a" stay different documents. The _id index follows the default collation, the lookups by _id and on
+// collections without a collation send none, which DocumentDB requires.
+func keyCollation(ctx context.Context, collection *mongo.Collection, key string) (*options.Collation, error) {
+	if key == "_id" {
+		return nil, nil
+	}
+	var specification struct {
+		Options struct {
+			Collation struct {
+				Locale string `bson:"locale"`
+			} `bson:"collation"`
+		} `bson:"options"`
+	}
+	err := withRetry(ctx, "listCollections "+collection.Database().Name(), func(int) error {
+		cursor, err := collection.Database().ListCollections(ctx, bson.D{{Key: "name", Value: collection.Name()}})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+		if cursor.Next(ctx) {
+			return cursor.Decode(&specification)
+		}
+		return cursor.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if locale := specification.Options.Collation.Locale; locale == "" || locale == "simple" {
+		return nil, nil
+	}
+	return &options.Collation{Locale: "simple"}, nil
+}
+
+func bulkWriteDocuments(ctx context.Context, collection *mongo.Collection, models []mongo.WriteModel) error {
+	if len(models) == 0 {
+		return nil
+	}
+	/* upserts and deletes by key can be applied again after a failure */
+	return withRetry(ctx, "bulkWrite "+collection.Database().Name()+"."+collection.Name(), func(int) error {
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		return err
+	})
+}
+
+// documentsHashes parses the documents argument and returns the hash and the document of each key.
+func documentsHashes(documents string, key string) (map[string]string, map[string]bson.Raw, error) {
+	value, err := parseExtendedJSON(documents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error decoding documents : %s ", err)
+	}
+	if value.Type != bsontype.Array {
+		return nil, nil, fmt.Errorf("documents must be a JSON array of documents")
+	}
+	elements, err := value.Array().Values()
+	if err != nil {
+		return nil, nil, err
+	}
+	var hashes = map[string]string{}
+	var parsed = map[string]bson.Raw{}
+	for index, element := range elements {
+		if element.Type != bsontype.EmbeddedDocument {
+			return nil, nil, fmt.Errorf("documents[%d] is not a JSON object", index)
+		}
+		encodedKey, hash, err := documentHash(element.Document(), key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("documents[%d] : %s", index, err)
+		}
+		if _, duplicate := hashes[encodedKey]; duplicate {
+			return nil, nil, fmt.Errorf("documents[%d] : duplicate key %s", index, encodedKey)
+		}
+		hashes[encodedKey] = hash
+		parsed[encodedKey] = element.Document()
+	}
+	return hashes, parsed, nil
+}
+
+// documentHash returns the canonical Extended JSON of the key of document and the SHA-256 of its canonical Extended JSON.
+// The _id is left out of the hash when it is not the key, the server generates it.
+func documentHash(document bson.Raw, key string) (string, string, error) {
+	keyValue, err := document.LookupErr(strings.Split(key, ".")...)
+	if err != nil {
+		return "", "", fmt.Errorf("missing key field %s", key)
+	}
+	encodedKey, err := formatCanonicalExtendedJSON(keyValue)
+	if err != nil {
+		return "", "", err
+	}
+
+	var hashed interface{} = document
+	if key != "_id" {
+		elements, err := document.Elements()
+		if err != nil {
+			return "", "", err
+		}
+		var withoutId bson.D
+		for _, element := range elements {
+			if element.Key() != "_id" {
+				withoutId = append(withoutId, bson.E{Key: element.Key(), Value: element.Value()})
+			}
+		}
+		hashed = withoutId
+	}
+	canonical, err := formatCanonicalExtendedJSON(hashed)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return encodedKey, hex.EncodeToString(sum[:]), nil
+}
+
+// documentsContentHash is the SHA-256 of the sorted hashes of the canonical Extended JSON documents, stored in place
+// of the documents argument. It ignores the formatting, the field order and the order of the documents.
+func documentsContentHash(documents string) (string, error) {
+	value, err := parseExtendedJSON(documents)
+	if err != nil {
+		return "", err
+	}
+	if value.Type != bsontype.Array {
+		return "", fmt.Errorf("documents must be a JSON array of documents")
+	}
+	elements, err := value.Array().Values()
+	if err != nil {
+		return "", err
+	}
+	var hashes []string
+	for _, element := range elements {
+		canonical, err := formatCanonicalExtendedJSON(element)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256([]byte(canonical))
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+	}
+	sort.Strings(hashes)
+	sum := sha256.Sum256([]byte(strings.Join(hashes, "\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func sameHashes(hashes map[string]string, state map[string]interface{}) bool {
+	if len(hashes) != len(state) {
+		return false
+	}
+	for key, hash := range hashes {
+		if state[key] != hash {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
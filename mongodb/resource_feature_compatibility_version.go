@@ -0,0 +1,189 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func resourceFeatureCompatibilityVersion() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFeatureCompatibilityVersionCreate,
+		ReadContext:   resourceFeatureCompatibilityVersionRead,
+		UpdateContext: resourceFeatureCompatibilityVersionUpdate,
+		DeleteContext: resourceFeatureCompatibilityVersionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		CustomizeDiff: featureCompatibilityVersionCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"version": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringMatch(regexp.MustCompile(`^\d+\.\d+$`), "must be a major.minor version such as 6.0")),
+				Description:      "The featureCompatibilityVersion",
+			},
+			"allow_downgrade": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow setting a version lower than the current one",
+			},
+		},
+	}
+}
+
+func resourceFeatureCompatibilityVersionCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	if err := setFeatureCompatibilityVersion(ctx, client, data.Get("version").(string), data.Get("allow_downgrade").(bool)); err != nil {
+		return diag.Errorf("Could not set the featureCompatibilityVersion : %s ", err)
+	}
+	data.SetId("featureCompatibilityVersion")
+	return resourceFeatureCompatibilityVersionRead(ctx, data, i)
+}
+
+func resourceFeatureCompatibilityVersionRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	current, err := featureCompatibilityVersion(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not read the featureCompatibilityVersion : %s ", err)
+	}
+	if err := data.Set("version", current); err != nil {
+		return diag.Errorf("error setting version : %s ", err)
+	}
+	return nil
+}
+
+func resourceFeatureCompatibilityVersionUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	if !data.HasChange("version") {
+		return resourceFeatureCompatibilityVersionRead(ctx, data, i)
+	}
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	if err := setFeatureCompatibilityVersion(ctx, client, data.Get("version").(string), data.Get("allow_downgrade").(bool)); err != nil {
+		return diag.Errorf("Could not set the featureCompatibilityVersion : %s ", err)
+	}
+	return resourceFeatureCompatibilityVersionRead(ctx, data, i)
+}
+
+/* the featureCompatibilityVersion cannot be removed, destroy leaves the current value in place */
+func resourceFeatureCompatibilityVersionDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	log.Printf("[INFO] featureCompatibilityVersion %s left in place", data.Get("version").(string))
+	return nil
+}
+
+/* a downgrade fails the plan rather than the apply, the server value is the one read into the state */
+func featureCompatibilityVersionCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() == "" || !diff.HasChange("version") || diff.Get("allow_downgrade").(bool) {
+		return nil
+	}
+	old, new := diff.GetChange("version")
+	if old.(string) == "" {
+		return nil
+	}
+	comparison, err := compareVersions(new.(string), old.(string))
+	if err != nil {
+		return err
+	}
+	if comparison < 0 {
+		return fmt.Errorf("%s is a downgrade from %s, set allow_downgrade to apply it", new, old)
+	}
+	return nil
+}
+
+func setFeatureCompatibilityVersion(ctx context.Context, client *mongo.Client, version string, allowDowngrade bool) error {
+	current, err := featureCompatibilityVersion(ctx, client)
+	if err != nil {
+		return err
+	}
+	comparison, err := compareVersions(version, current)
+	if err != nil {
+		return err
+	}
+	if comparison == 0 {
+		return nil
+	}
+	if comparison < 0 && !allowDowngrade {
+		return fmt.Errorf("%s is a downgrade from %s, set allow_downgrade to apply it", version, current)
+	}
+
+	build, err := buildInfo(ctx, client)
+	if err != nil {
+		return err
+	}
+	command := bson.D{{Key: "setFeatureCompatibilityVersion", Value: version}}
+	/* 7.0+ refuses the command without an explicit confirmation */
+	if len(build.VersionArray) > 0 && build.VersionArray[0] >= 7 {
+		command = append(command, bson.E{Key: "confirm", Value: true})
+	}
+	log.Printf("[INFO] setting featureCompatibilityVersion from %s to %s", current, version)
+	return withRetry(ctx, "setFeatureCompatibilityVersion "+version, func(int) error {
+		return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Err()
+	})
+}
+
+// compareVersions compares two major.minor versions, like strings.Compare.
+func compareVersions(a string, b string) (int, error) {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for index := 0; index < len(aParts) || index < len(bParts); index++ {
+		var aPart, bPart int
+		var err error
+		if index < len(aParts) {
+			if aPart, err = strconv.Atoi(aParts[index]); err != nil {
+				return 0, fmt.Errorf("invalid version %s", a)
+			}
+		}
+		if index < len(bParts) {
+			if bPart, err = strconv.Atoi(bParts[index]); err != nil {
+				return 0, fmt.Errorf("invalid version %s", b)
+			}
+		}
+		if aPart != bPart {
+			if aPart < bPart {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,283 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"strings"
+	"time"
+)
+
+func resourceMaterializedView() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMaterializedViewCreate,
+		ReadContext:   resourceMaterializedViewRead,
+		UpdateContext: resourceMaterializedViewUpdate,
+		DeleteContext: resourceMaterializedViewDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"source": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The source collection of the aggregation",
+			},
+			"pipeline": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				DiffSuppressFunc: suppressEquivalentOrderedExtendedJSON,
+				Description:      "The aggregation pipeline stages, an Extended JSON array without the output stage",
+			},
+			"target": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The collection holding the results",
+			},
+			"stage": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "merge",
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"merge", "out"}, false)),
+				Description:      "merge updates the target with $merge, out replaces it with $out",
+			},
+			"on": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The fields identifying a target document for $merge, defaults to _id",
+			},
+			"when_matched": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "replace",
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"replace", "keepExisting", "merge", "fail"}, false)),
+			},
+			"when_not_matched": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "insert",
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice([]string{"insert", "discard", "fail"}, false)),
+			},
+			"refresh_trigger": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values, any change runs the aggregation again",
+			},
+			"drop_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Drop the target collection on destroy",
+			},
+			"last_refresh": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "RFC 3339 time of the last run of the aggregation",
+			},
+			"document_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of documents of the target after the last run",
+			},
+		},
+	}
+}
+
+func resourceMaterializedViewCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var target = data.Get("target").(string)
+	if err := refreshMaterializedView(ctx, client, database, data); err != nil {
+		return diag.Errorf("Could not refresh %s.%s : %s ", database, target, err)
+	}
+	data.SetId(base64.StdEncoding.EncodeToString([]byte(database + "." + target)))
+	return resourceMaterializedViewRead(ctx, data, i)
+}
+
+func resourceMaterializedViewRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	target, database, err := resourceMaterializedViewParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	var names []string
+	err = withRetry(ctx, "listCollections "+database, func(int) error {
+		var err error
+		names, err = client.Database(database).ListCollectionNames(ctx, bson.D{{Key: "name", Value: target}})
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not read %s.%s : %s ", database, target, err)
+	}
+	/* a dropped target is created again by the next apply */
+	if len(names) == 0 {
+		log.Printf("[WARN] target collection %s.%s not found, removing from state", database, target)
+		data.SetId("")
+		return nil
+	}
+	return nil
+}
+
+func resourceMaterializedViewUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	if !data.HasChanges("source", "pipeline", "stage", "on", "when_matched", "when_not_matched", "refresh_trigger") {
+		return resourceMaterializedViewRead(ctx, data, i)
+	}
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	target, database, err := resourceMaterializedViewParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	if err := refreshMaterializedView(ctx, client, database, data); err != nil {
+		return diag.Errorf("Could not refresh %s.%s : %s ", database, target, err)
+	}
+	return resourceMaterializedViewRead(ctx, data, i)
+}
+
+func resourceMaterializedViewDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	if !data.Get("drop_on_destroy").(bool) {
+		return nil
+	}
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	target, database, err := resourceMaterializedViewParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	err = withRetry(ctx, "drop "+database+"."+target, func(int) error {
+		return client.Database(database).Collection(target).Drop(ctx)
+	})
+	if err != nil {
+		return diag.Errorf("Could not drop %s.%s : %s ", database, target, err)
+	}
+	return nil
+}
+
+// refreshMaterializedView runs the pipeline followed by the output stage, then records the time and the target size.
+func refreshMaterializedView(ctx context.Context, client *mongo.Client, database string, data *schema.ResourceData) error {
+	var target = data.Get("target").(string)
+	var source = data.Get("source").(string)
+	stages, err := materializedViewPipeline(database, data)
+	if err != nil {
+		return err
+	}
+
+	err = withRetry(ctx, "aggregate "+database+"."+source, func(int) error {
+		cursor, err := client.Database(database).Collection(source).Aggregate(ctx, stages, options.Aggregate().SetAllowDiskUse(true))
+		if err != nil {
+			return err
+		}
+		return cursor.Close(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	var refreshed = time.Now().UTC()
+
+	var count int64
+	err = withRetry(ctx, "count "+database+"."+target, func(int) error {
+		var err error
+		count, err = client.Database(database).Collection(target).CountDocuments(ctx, bson.D{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] refreshed %s.%s, %d documents", database, target, count)
+
+	if err := data.Set("last_refresh", refreshed.Format(time.RFC3339)); err != nil {
+		return err
+	}
+	return data.Set("document_count", int(count))
+}
+
+func materializedViewPipeline(database string, data *schema.ResourceData) (bson.A, error) {
+	pipeline, err := viewPipeline(data.Get("pipeline").(string))
+	if err != nil {
+		return nil, err
+	}
+	var stages bson.A
+	if err := pipeline.Unmarshal(&stages); err != nil {
+		return nil, err
+	}
+	if len(stages) > 0 {
+		if last, ok := stages[len(stages)-1].(bson.D); ok && len(last) > 0 && (last[0].Key == "$merge" || last[0].Key == "$out") {
+			return nil, fmt.Errorf("the pipeline must not end with %s, the output stage is added from target", last[0].Key)
+		}
+	}
+
+	var into = bson.D{{Key: "db", Value: database}, {Key: "coll", Value: data.Get("target").(string)}}
+	if data.Get("stage").(string) == "out" {
+		return append(stages, bson.D{{Key: "$out", Value: into}}), nil
+	}
+	merge := bson.D{
+		{Key: "into", Value: into},
+		{Key: "whenMatched", Value: data.Get("when_matched").(string)},
+		{Key: "whenNotMatched", Value: data.Get("when_not_matched").(string)},
+	}
+	if on := data.Get("on").([]interface{}); len(on) > 0 {
+		merge = append(merge, bson.E{Key: "on", Value: on})
+	}
+	return append(stages, bson.D{{Key: "$merge", Value: merge}}), nil
+}
+
+func resourceMaterializedViewParseId(id string) (string, string, error) {
+	result, errEncoding := base64.StdEncoding.DecodeString(id)
+
+	if errEncoding != nil {
+		return "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(result), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected database.target", id)
+	}
+	return parts[1], parts[0], nil
+}
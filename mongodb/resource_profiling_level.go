@@ -0,0 +1,212 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+type SingleResultProfile struct {
+	Was        int32    `bson:"was"`
+	Slowms     int32    `bson:"slowms"`
+	SampleRate float64  `bson:"sampleRate"`
+	Filter     bson.Raw `bson:"filter,omitempty"`
+}
+
+func resourceProfilingLevel() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceProfilingLevelCreate,
+		ReadContext:   resourceProfilingLevelRead,
+		UpdateContext: resourceProfilingLevelUpdate,
+		DeleteContext: resourceProfilingLevelDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The profiled database",
+			},
+			"level": {
+				Type:             schema.TypeInt,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.IntBetween(0, 2)),
+				Description:      "0 is off, 1 profiles the slow operations, 2 profiles every operation",
+			},
+			"slowms": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          -1,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(-1)),
+				Description:      "Threshold in milliseconds of a slow operation, -1 keeps the server value",
+			},
+			"sample_rate": {
+				Type:     schema.TypeFloat,
+				Optional: true,
+				Default:  -1.0,
+				ValidateDiagFunc: validateDiagFunc(validation.Any(
+					validation.FloatBetween(0, 1),
+					validation.FloatBetween(-1, -1),
+				)),
+				Description: "Fraction of the slow operations profiled, -1 keeps the server value",
+			},
+			"filter": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				DiffSuppressFunc: suppressEquivalentJSON,
+				Description:      "Query filter ( JSON ) selecting the profiled operations, 4.4.2+",
+			},
+		},
+	}
+}
+
+func resourceProfilingLevelCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	err := setProfilingLevel(ctx, client, database, data)
+	if err != nil {
+		return diag.Errorf("Could not set the profiling level of %s : %s ", database, err)
+	}
+	data.SetId(database)
+	return resourceProfilingLevelRead(ctx, data, i)
+}
+
+func resourceProfilingLevelRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Id()
+	result, err := getProfilingLevel(ctx, client, database)
+	if err != nil {
+		return diag.Errorf("Could not read the profiling level of %s : %s ", database, err)
+	}
+
+	if err := data.Set("database", database); err != nil {
+		return diag.Errorf("error setting database : %s ", err)
+	}
+	if err := data.Set("level", int(result.Was)); err != nil {
+		return diag.Errorf("error setting level : %s ", err)
+	}
+	// -1 leaves the server wide value unmanaged
+	if data.Get("slowms").(int) != -1 {
+		if err := data.Set("slowms", int(result.Slowms)); err != nil {
+			return diag.Errorf("error setting slowms : %s ", err)
+		}
+	}
+	if data.Get("sample_rate").(float64) != -1 {
+		if err := data.Set("sample_rate", result.SampleRate); err != nil {
+			return diag.Errorf("error setting sample_rate : %s ", err)
+		}
+	}
+	var filter string
+	if len(result.Filter) > 0 {
+		document, err := bson.MarshalExtJSON(result.Filter, false, false)
+		if err != nil {
+			return diag.Errorf("error decoding filter : %s ", err)
+		}
+		filter = string(document)
+	}
+	if err := data.Set("filter", filter); err != nil {
+		return diag.Errorf("error setting filter : %s ", err)
+	}
+	return nil
+}
+
+func resourceProfilingLevelUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Id()
+	err := setProfilingLevel(ctx, client, database, data)
+	if err != nil {
+		return diag.Errorf("Could not set the profiling level of %s : %s ", database, err)
+	}
+	return resourceProfilingLevelRead(ctx, data, i)
+}
+
+func resourceProfilingLevelDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Id()
+	command := bson.D{{Key: "profile", Value: 0}}
+	if data.Get("filter").(string) != "" {
+		command = append(command, bson.E{Key: "filter", Value: "unset"})
+	}
+	err := withRetry(ctx, "profile "+database, func(int) error {
+		return client.Database(database).RunCommand(ctx, command).Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not reset the profiling level of %s : %s ", database, err)
+	}
+	return nil
+}
+
+func setProfilingLevel(ctx context.Context, client *mongo.Client, database string, data *schema.ResourceData) error {
+	command := bson.D{{Key: "profile", Value: data.Get("level").(int)}}
+	// slowms and sampleRate are only sent when configured, they are server wide, 0 is a valid value
+	if slowms := data.Get("slowms").(int); slowms != -1 {
+		command = append(command, bson.E{Key: "slowms", Value: slowms})
+	}
+	if sampleRate := data.Get("sample_rate").(float64); sampleRate != -1 {
+		command = append(command, bson.E{Key: "sampleRate", Value: sampleRate})
+	}
+	if filter := data.Get("filter").(string); filter != "" {
+		var document bson.D
+		if err := bson.UnmarshalExtJSON([]byte(filter), false, &document); err != nil {
+			return err
+		}
+		command = append(command, bson.E{Key: "filter", Value: document})
+	} else if data.HasChange("filter") {
+		command = append(command, bson.E{Key: "filter", Value: "unset"})
+	}
+	return withRetry(ctx, "profile "+database, func(int) error {
+		return client.Database(database).RunCommand(ctx, command).Err()
+	})
+}
+
+func getProfilingLevel(ctx context.Context, client *mongo.Client, database string) (SingleResultProfile, error) {
+	var decodedResult SingleResultProfile
+	err := withRetry(ctx, "profile "+database, func(int) error {
+		return client.Database(database).RunCommand(ctx, bson.D{{Key: "profile", Value: -1}}).Decode(&decodedResult)
+	})
+	return decodedResult, err
+}
@@ -0,0 +1,402 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type SingleResultReplSetGetConfig struct {
+	Config bson.D `bson:"config"`
+}
+
+func resourceReplicaSetConfig() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceReplicaSetConfigCreate,
+		ReadContext:   resourceReplicaSetConfigRead,
+		UpdateContext: resourceReplicaSetConfigUpdate,
+		DeleteContext: resourceReplicaSetConfigDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"member": {
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: "The settings of the managed members, the members left out are not changed",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The member host:port, as in the replica set config",
+						},
+						"priority": {
+							Type:             schema.TypeFloat,
+							Optional:         true,
+							Default:          1,
+							ValidateDiagFunc: validateDiagFunc(validation.FloatBetween(0, 1000)),
+						},
+						"votes": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          1,
+							ValidateDiagFunc: validateDiagFunc(validation.IntBetween(0, 1)),
+						},
+						"hidden": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"secondary_delay_secs": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          0,
+							ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(0)),
+						},
+						"tags": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"set_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The version of the replica set config",
+			},
+		},
+	}
+}
+
+func resourceReplicaSetConfigCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	if err := reconfigureReplicaSet(ctx, client, data.Get("member").([]interface{})); err != nil {
+		return diag.Errorf("Could not reconfigure the replica set : %s ", err)
+	}
+	current, err := getReplicaSetConfig(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not read the replica set config : %s ", err)
+	}
+	setName, _ := documentValue(current, "_id")
+	data.SetId(fmt.Sprintf("%v", setName))
+	return resourceReplicaSetConfigRead(ctx, data, i)
+}
+
+func resourceReplicaSetConfigRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	current, err := getReplicaSetConfig(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not read the replica set config : %s ", err)
+	}
+	setName, _ := documentValue(current, "_id")
+	if fmt.Sprintf("%v", setName) != data.Id() {
+		return diag.Errorf("connected to the replica set %v, expected %s", setName, data.Id())
+	}
+	version, _ := documentValue(current, "version")
+
+	/* the managed members keep the order of the configuration, an import takes every member but the arbiters */
+	members := replicaSetConfigMembers(current)
+	var states []interface{}
+	if configured := data.Get("member").([]interface{}); len(configured) > 0 {
+		for _, configuredMember := range configured {
+			host := configuredMember.(map[string]interface{})["host"].(string)
+			if member, ok := members[host]; ok {
+				states = append(states, replicaSetMemberState(member))
+			}
+		}
+	} else {
+		for _, member := range documentArray(current, "members") {
+			if arbiter, _ := documentValue(member, "arbiterOnly"); arbiter == true {
+				continue
+			}
+			states = append(states, replicaSetMemberState(member))
+		}
+	}
+
+	if err := data.Set("member", states); err != nil {
+		return diag.Errorf("error setting member : %s ", err)
+	}
+	if err := data.Set("set_name", data.Id()); err != nil {
+		return diag.Errorf("error setting set_name : %s ", err)
+	}
+	if err := data.Set("version", int(numberValue(version))); err != nil {
+		return diag.Errorf("error setting version : %s ", err)
+	}
+	return nil
+}
+
+func resourceReplicaSetConfigUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	if err := reconfigureReplicaSet(ctx, client, data.Get("member").([]interface{})); err != nil {
+		return diag.Errorf("Could not reconfigure the replica set : %s ", err)
+	}
+	return resourceReplicaSetConfigRead(ctx, data, i)
+}
+
+/* the members keep their current settings, destroy only forgets them */
+func resourceReplicaSetConfigDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	log.Printf("[INFO] replica set %s config left in place", data.Id())
+	return nil
+}
+
+// reconfigureReplicaSet applies the member settings with as many replSetReconfig as needed : the members keeping their
+// votes first, then each member whose votes change in its own reconfig, as only one voting member may be added or
+// removed at a time.
+func reconfigureReplicaSet(ctx context.Context, client *mongo.Client, configured []interface{}) error {
+	current, err := getReplicaSetConfig(ctx, client)
+	if err != nil {
+		return err
+	}
+	members := replicaSetConfigMembers(current)
+
+	var sameVotes = map[string]map[string]interface{}{}
+	var votingChanges []map[string]interface{}
+	for _, configuredMember := range configured {
+		desired := configuredMember.(map[string]interface{})
+		host := desired["host"].(string)
+		member, ok := members[host]
+		if !ok {
+			return fmt.Errorf("%s is not a member of the replica set", host)
+		}
+		votes, _ := documentValue(member, "votes")
+		if int(numberValue(votes)) == desired["votes"].(int) {
+			sameVotes[host] = desired
+		} else {
+			votingChanges = append(votingChanges, desired)
+		}
+	}
+
+	if err := reconfigureMembers(ctx, client, sameVotes); err != nil {
+		return err
+	}
+	for _, desired := range votingChanges {
+		if err := reconfigureMembers(ctx, client, map[string]map[string]interface{}{desired["host"].(string): desired}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconfigureMembers applies the settings of desired, by host, on the current config with a version bump. The config is
+// read again on each attempt, so a retried reconfig that was already applied does nothing.
+func reconfigureMembers(ctx context.Context, client *mongo.Client, desired map[string]map[string]interface{}) error {
+	if len(desired) == 0 {
+		return nil
+	}
+	return withRetry(ctx, "replSetReconfig", func(int) error {
+		current, err := getReplicaSetConfig(ctx, client)
+		if err != nil {
+			return err
+		}
+		var changed bool
+		var members bson.A
+		for _, member := range documentArray(current, "members") {
+			host, _ := documentValue(member, "host")
+			if settings, ok := desired[fmt.Sprintf("%v", host)]; ok {
+				updated := applyMemberSettings(member, settings)
+				changed = changed || !reflect.DeepEqual(updated, member)
+				member = updated
+			}
+			members = append(members, member)
+		}
+		if !changed {
+			return nil
+		}
+		version, _ := documentValue(current, "version")
+		next := setDocumentValue(current, "members", members)
+		next = setDocumentValue(next, "version", int64(numberValue(version))+1)
+		/* the primary sets the term of the new config itself */
+		next = removeDocumentValue(next, "term")
+		log.Printf("[INFO] replSetReconfig to version %d", int64(numberValue(version))+1)
+		return client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetReconfig", Value: next}}, options.RunCmd().SetReadPreference(readpref.Primary())).Err()
+	})
+}
+
+func getReplicaSetConfig(ctx context.Context, client *mongo.Client) (bson.D, error) {
+	var decodedResult SingleResultReplSetGetConfig
+	err := withRetry(ctx, "replSetGetConfig", func(int) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetConfig", Value: 1}}, options.RunCmd().SetReadPreference(readpref.Primary())).Decode(&decodedResult)
+	})
+	return decodedResult.Config, err
+}
+
+func replicaSetConfigMembers(config bson.D) map[string]bson.D {
+	members := map[string]bson.D{}
+	for _, member := range documentArray(config, "members") {
+		host, _ := documentValue(member, "host")
+		members[fmt.Sprintf("%v", host)] = member
+	}
+	return members
+}
+
+func replicaSetMemberState(member bson.D) map[string]interface{} {
+	host, _ := documentValue(member, "host")
+	priority, _ := documentValue(member, "priority")
+	votes, _ := documentValue(member, "votes")
+	hidden, _ := documentValue(member, "hidden")
+	delay, _ := documentValue(member, memberDelayField(member))
+	tags := map[string]interface{}{}
+	if memberTags, ok := documentValue(member, "tags"); ok {
+		if document, ok := memberTags.(bson.D); ok {
+			for _, tag := range document {
+				tags[tag.Key] = fmt.Sprintf("%v", tag.Value)
+			}
+		}
+	}
+	isHidden, _ := hidden.(bool)
+	return map[string]interface{}{
+		"host":                 fmt.Sprintf("%v", host),
+		"priority":             numberValue(priority),
+		"votes":                int(numberValue(votes)),
+		"hidden":               isHidden,
+		"secondary_delay_secs": int(numberValue(delay)),
+		"tags":                 tags,
+	}
+}
+
+func applyMemberSettings(member bson.D, settings map[string]interface{}) bson.D {
+	var tags bson.D
+	configuredTags := settings["tags"].(map[string]interface{})
+	keys := make([]string, 0, len(configuredTags))
+	for key := range configuredTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tags = append(tags, bson.E{Key: key, Value: configuredTags[key]})
+	}
+
+	var updated = append(bson.D{}, member...)
+	if priority, _ := documentValue(member, "priority"); numberValue(priority) != settings["priority"].(float64) {
+		updated = setDocumentValue(updated, "priority", settings["priority"].(float64))
+	}
+	if votes, _ := documentValue(member, "votes"); int(numberValue(votes)) != settings["votes"].(int) {
+		updated = setDocumentValue(updated, "votes", int32(settings["votes"].(int)))
+	}
+	if hidden, _ := documentValue(member, "hidden"); hidden != settings["hidden"].(bool) {
+		updated = setDocumentValue(updated, "hidden", settings["hidden"].(bool))
+	}
+	var delayField = memberDelayField(member)
+	if delay, _ := documentValue(member, delayField); int(numberValue(delay)) != settings["secondary_delay_secs"].(int) {
+		updated = setDocumentValue(updated, delayField, int64(settings["secondary_delay_secs"].(int)))
+	}
+	if current := replicaSetMemberState(member)["tags"]; !reflect.DeepEqual(current, configuredTags) {
+		if tags == nil {
+			tags = bson.D{}
+		}
+		updated = setDocumentValue(updated, "tags", tags)
+	}
+	return updated
+}
+
+// memberDelayField is secondaryDelaySecs from MongoDB 5.0, slaveDelay before.
+func memberDelayField(member bson.D) string {
+	if _, ok := documentValue(member, "slaveDelay"); ok {
+		return "slaveDelay"
+	}
+	return "secondaryDelaySecs"
+}
+
+func documentValue(document bson.D, key string) (interface{}, bool) {
+	for _, element := range document {
+		if element.Key == key {
+			return element.Value, true
+		}
+	}
+	return nil, false
+}
+
+func documentArray(document bson.D, key string) []bson.D {
+	value, _ := documentValue(document, key)
+	array, _ := value.(bson.A)
+	var documents []bson.D
+	for _, element := range array {
+		if item, ok := element.(bson.D); ok {
+			documents = append(documents, item)
+		}
+	}
+	return documents
+}
+
+// setDocumentValue returns a copy of document with key set to value, appended when missing.
+func setDocumentValue(document bson.D, key string, value interface{}) bson.D {
+	updated := append(bson.D{}, document...)
+	for index, element := range updated {
+		if element.Key == key {
+			updated[index].Value = value
+			return updated
+		}
+	}
+	return append(updated, bson.E{Key: key, Value: value})
+}
+
+func removeDocumentValue(document bson.D, key string) bson.D {
+	var updated bson.D
+	for _, element := range document {
+		if element.Key != key {
+			updated = append(updated, element)
+		}
+	}
+	return updated
+}
+
+func numberValue(value interface{}) float64 {
+	switch typed := value.(type) {
+	case int32:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case float64:
+		return typed
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,235 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"net"
+	"time"
+)
+
+func resourceReplicaSetInitiate() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceReplicaSetInitiateCreate,
+		ReadContext:   resourceReplicaSetInitiateRead,
+		DeleteContext: resourceReplicaSetInitiateDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"seed": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The host:port of the member receiving replSetInitiate, defaults to the provider host and port",
+			},
+			"unauthenticated": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Connect to the seed without credentials, e.g. through the localhost exception of a fresh node started with auth",
+			},
+			"set_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"member": {
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: "The member host:port, as the other members reach it",
+						},
+						"priority": {
+							Type:             schema.TypeFloat,
+							Optional:         true,
+							ForceNew:         true,
+							Default:          1,
+							ValidateDiagFunc: validateDiagFunc(validation.FloatBetween(0, 1000)),
+						},
+						"votes": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							ForceNew:         true,
+							Default:          1,
+							ValidateDiagFunc: validateDiagFunc(validation.IntBetween(0, 1)),
+						},
+						"arbiter_only": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"primary": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The primary elected after the initiation",
+			},
+		},
+	}
+}
+
+func resourceReplicaSetInitiateCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var setName = data.Get("set_name").(string)
+	client, err := replicaSetSeedClient(ctx, i.(*MongoDatabaseConfiguration), data)
+	if err != nil {
+		return diag.Errorf("Error connecting to database : %s ", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	current, err := isMaster(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not run isMaster : %s ", err)
+	}
+	switch current.SetName {
+	case setName:
+		log.Printf("[INFO] replica set %s is already initiated", setName)
+	case "":
+		if err := initiateReplicaSet(ctx, client, setName, data.Get("member").([]interface{})); err != nil {
+			return diag.Errorf("Could not initiate the replica set %s : %s ", setName, err)
+		}
+	default:
+		return diag.Errorf("the seed is already a member of the replica set %s", current.SetName)
+	}
+
+	primary, err := waitForPrimary(ctx, client)
+	if err != nil {
+		return diag.Errorf("No primary elected in replica set %s : %s ", setName, err)
+	}
+	if err := data.Set("primary", primary); err != nil {
+		return diag.Errorf("error setting primary : %s ", err)
+	}
+	data.SetId(setName)
+	return nil
+}
+
+func resourceReplicaSetInitiateRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	client, err := replicaSetSeedClient(ctx, i.(*MongoDatabaseConfiguration), data)
+	if err != nil {
+		return diag.Errorf("Error connecting to database : %s ", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	current, err := isMaster(ctx, client)
+	if err != nil {
+		return diag.Errorf("Could not run isMaster : %s ", err)
+	}
+	/* a seed that is no longer initiated ( e.g. a recreated dev environment ) is initiated again */
+	if current.SetName == "" {
+		log.Printf("[WARN] %s is not a replica set member, removing from state", data.Id())
+		data.SetId("")
+		return nil
+	}
+	if current.SetName != data.Id() {
+		return diag.Errorf("the seed is a member of the replica set %s, expected %s", current.SetName, data.Id())
+	}
+	if err := data.Set("primary", current.Primary); err != nil {
+		return diag.Errorf("error setting primary : %s ", err)
+	}
+	return nil
+}
+
+/* a replica set cannot be taken back to its uninitiated state, destroy only forgets it */
+func resourceReplicaSetInitiateDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	log.Printf("[INFO] replica set %s left initiated", data.Id())
+	return nil
+}
+
+// replicaSetSeedClient connects directly to the seed, discovery cannot find the members before the initiation.
+func replicaSetSeedClient(ctx context.Context, config *MongoDatabaseConfiguration, data *schema.ResourceData) (*mongo.Client, error) {
+	profile, err := config.profile(data.Get("connection").(string))
+	if err != nil {
+		return nil, err
+	}
+	/* isMaster and replSetInitiate under the localhost exception need no user, and a fresh node has none */
+	if data.Get("unauthenticated").(bool) {
+		anonymous := *profile.Config
+		anonymous.Username = ""
+		anonymous.Password = ""
+		anonymous.CredentialsSource = nil
+		profile = &MongoDatabaseConfiguration{Config: &anonymous, MaxConnLifetime: profile.MaxConnLifetime}
+	}
+	var seed = data.Get("seed").(string)
+	if seed == "" {
+		seed = net.JoinHostPort(profile.Config.Host, profile.Config.Port)
+	}
+	return memberClient(ctx, profile, seed)
+}
+
+func initiateReplicaSet(ctx context.Context, client *mongo.Client, setName string, configured []interface{}) error {
+	var members bson.A
+	for index, configuredMember := range configured {
+		member := configuredMember.(map[string]interface{})
+		memberConfig := bson.D{
+			{Key: "_id", Value: int32(index)},
+			{Key: "host", Value: member["host"].(string)},
+			{Key: "votes", Value: int32(member["votes"].(int))},
+		}
+		/* an arbiter always has a priority of 0 */
+		if member["arbiter_only"].(bool) {
+			memberConfig = append(memberConfig, bson.E{Key: "arbiterOnly", Value: true})
+		} else {
+			memberConfig = append(memberConfig, bson.E{Key: "priority", Value: member["priority"].(float64)})
+		}
+		members = append(members, memberConfig)
+	}
+	command := bson.D{{Key: "replSetInitiate", Value: bson.D{{Key: "_id", Value: setName}, {Key: "members", Value: members}}}}
+	return withRetry(ctx, "replSetInitiate "+setName, func(attempt int) error {
+		err := client.Database("admin").RunCommand(ctx, command).Err()
+		/* the previous attempt may have been applied before its error came back */
+		if err != nil && attempt > 1 && hasErrorCode(err, errorCodeAlreadyInitialized) {
+			return nil
+		}
+		return err
+	})
+}
+
+// waitForPrimary polls isMaster until the replica set reports a primary or ctx is done.
+func waitForPrimary(ctx context.Context, client *mongo.Client) (string, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		current, err := isMaster(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		if current.Primary != "" {
+			return current.Primary, nil
+		}
+		log.Printf("[INFO] waiting for replica set %s to elect a primary", current.SetName)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("gave up waiting : %s", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"strconv"
+	"time"
+)
+
+var serverParameterTypes = []string{"string", "int", "long", "double", "bool", "document"}
+
+func resourceServerParameter() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceServerParameterCreate,
+		ReadContext:   resourceServerParameterRead,
+		UpdateContext: resourceServerParameterUpdate,
+		DeleteContext: resourceServerParameterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The server parameter name",
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The value, Extended JSON for a document",
+			},
+			"type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringInSlice(serverParameterTypes, false)),
+				Description:      "The BSON type of the value, defaults to the type of the current value",
+			},
+			"previous_value": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value captured at creation, restored on destroy",
+			},
+			"previous_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceServerParameterCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var name = data.Get("name").(string)
+
+	previous, err := getServerParameter(ctx, client, name)
+	if err != nil {
+		return diag.Errorf("Could not read the parameter %s : %s ", name, err)
+	}
+	previousValue, previousType, err := formatServerParameter(previous)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	var valueType = data.Get("type").(string)
+	if valueType == "" {
+		valueType = previousType
+	}
+
+	err = setServerParameterOnMembers(ctx, config, data.Get("connection").(string), client, name, data.Get("value").(string), valueType)
+	if err != nil {
+		return diag.Errorf("Could not set the parameter %s : %s ", name, err)
+	}
+
+	data.SetId(name)
+	if err := data.Set("type", valueType); err != nil {
+		return diag.Errorf("error setting type : %s ", err)
+	}
+	if err := data.Set("previous_value", previousValue); err != nil {
+		return diag.Errorf("error setting previous_value : %s ", err)
+	}
+	if err := data.Set("previous_type", previousType); err != nil {
+		return diag.Errorf("error setting previous_type : %s ", err)
+	}
+	return resourceServerParameterRead(ctx, data, i)
+}
+
+func resourceServerParameterRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	var connection = data.Get("connection").(string)
+	client, connectionError := MongoClientInit(ctx, config, connection)
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var name = data.Id()
+	profile, err := config.profile(connection)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	// the value of the first member that differs from the state, so drift on any member shows up
+	var stateValue = data.Get("value").(string)
+	var expected = normalizeServerParameter(stateValue, data.Get("type").(string))
+	var currentValue, currentType string
+	var drifted bool
+	err = eachMember(ctx, profile, client, func(member string, memberClient *mongo.Client) error {
+		value, err := getServerParameter(ctx, memberClient, name)
+		if err != nil {
+			return err
+		}
+		formatted, formattedType, err := formatServerParameter(value)
+		if err != nil {
+			return err
+		}
+		if currentType == "" || (!drifted && formatted != expected) {
+			currentValue, currentType = formatted, formattedType
+			drifted = formatted != expected
+			if drifted && member != "" {
+				log.Printf("[WARN] parameter %s is %s on %s", name, formatted, member)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.Errorf("Could not read the parameter %s : %s ", name, err)
+	}
+	if !drifted {
+		// keep the value as written in the configuration
+		currentValue = stateValue
+	}
+
+	if err := data.Set("name", name); err != nil {
+		return diag.Errorf("error setting name : %s ", err)
+	}
+	if err := data.Set("value", currentValue); err != nil {
+		return diag.Errorf("error setting value : %s ", err)
+	}
+	if data.Get("type").(string) == "" {
+		if err := data.Set("type", currentType); err != nil {
+			return diag.Errorf("error setting type : %s ", err)
+		}
+	}
+	// imported, the current value is the one restored on destroy
+	if data.Get("previous_type").(string) == "" {
+		if err := data.Set("previous_value", currentValue); err != nil {
+			return diag.Errorf("error setting previous_value : %s ", err)
+		}
+		if err := data.Set("previous_type", currentType); err != nil {
+			return diag.Errorf("error setting previous_type : %s ", err)
+		}
+	}
+	return nil
+}
+
+func resourceServerParameterUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	var connection = data.Get("connection").(string)
+	client, connectionError := MongoClientInit(ctx, config, connection)
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var name = data.Id()
+	err := setServerParameterOnMembers(ctx, config, connection, client, name, data.Get("value").(string), data.Get("type").(string))
+	if err != nil {
+		return diag.Errorf("Could not set the parameter %s : %s ", name, err)
+	}
+	return resourceServerParameterRead(ctx, data, i)
+}
+
+func resourceServerParameterDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	var connection = data.Get("connection").(string)
+	client, connectionError := MongoClientInit(ctx, config, connection)
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var name = data.Id()
+	err := setServerParameterOnMembers(ctx, config, connection, client, name, data.Get("previous_value").(string), data.Get("previous_type").(string))
+	if err != nil {
+		return diag.Errorf("Could not restore the parameter %s : %s ", name, err)
+	}
+	return nil
+}
+
+func getServerParameter(ctx context.Context, client *mongo.Client, name string) (interface{}, error) {
+	var result bson.D
+	err := withRetry(ctx, "getParameter "+name, func(int) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{{Key: "getParameter", Value: 1}, {Key: name, Value: 1}}).Decode(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range result {
+		if element.Key == name {
+			return element.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown parameter %s", name)
+}
+
+func setServerParameterOnMembers(ctx context.Context, config *MongoDatabaseConfiguration, connection string, client *mongo.Client, name string, value string, valueType string) error {
+	parsed, err := parseServerParameter(value, valueType)
+	if err != nil {
+		return err
+	}
+	profile, err := config.profile(connection)
+	if err != nil {
+		return err
+	}
+	return eachMember(ctx, profile, client, func(member string, memberClient *mongo.Client) error {
+		err := withRetry(ctx, "setParameter "+name, func(int) error {
+			return memberClient.Database("admin").RunCommand(ctx, bson.D{{Key: "setParameter", Value: 1}, {Key: name, Value: parsed}}).Err()
+		})
+		if err != nil && member != "" {
+			return fmt.Errorf("%s : %s", member, err)
+		}
+		return err
+	})
+}
+
+func parseServerParameter(value string, valueType string) (interface{}, error) {
+	switch valueType {
+	case "int":
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return int32(parsed), err
+	case "long":
+		return strconv.ParseInt(value, 10, 64)
+	case "double":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	case "document":
+		var document bson.D
+		err := bson.UnmarshalExtJSON([]byte(value), false, &document)
+		return document, err
+	default:
+		return value, nil
+	}
+}
+
+// normalizeServerParameter formats value the way formatServerParameter formats the server one.
+func normalizeServerParameter(value string, valueType string) string {
+	parsed, err := parseServerParameter(value, valueType)
+	if err != nil {
+		return value
+	}
+	formatted, _, err := formatServerParameter(parsed)
+	if err != nil {
+		return value
+	}
+	return formatted
+}
+
+func formatServerParameter(value interface{}) (string, string, error) {
+	switch typed := value.(type) {
+	case string:
+		return typed, "string", nil
+	case int32:
+		return strconv.FormatInt(int64(typed), 10), "int", nil
+	case int64:
+		return strconv.FormatInt(typed, 10), "long", nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), "double", nil
+	case bool:
+		return strconv.FormatBool(typed), "bool", nil
+	case bson.D, bson.M:
+		document, err := bson.MarshalExtJSON(typed, false, false)
+		if err != nil {
+			return "", "", err
+		}
+		canonical, err := canonicalJSON(document)
+		return canonical, "document", err
+	default:
+		return "", "", fmt.Errorf("unsupported parameter type %T", value)
+	}
+}
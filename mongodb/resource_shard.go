@@ -0,0 +1,226 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"strings"
+	"time"
+)
+
+/* how often destroy asks removeShard for the draining progress */
+const shardDrainingInterval = 10 * time.Second
+
+type SingleResultAddShard struct {
+	ShardAdded string `bson:"shardAdded"`
+}
+
+type SingleResultRemoveShard struct {
+	Msg   string `bson:"msg"`
+	State string `bson:"state"`
+	// Remaining is only reported once the draining is ongoing
+	Remaining *struct {
+		Chunks      int64 `bson:"chunks"`
+		Dbs         int64 `bson:"dbs"`
+		JumboChunks int64 `bson:"jumboChunks"`
+	} `bson:"remaining"`
+	DbsToMove []string `bson:"dbsToMove"`
+}
+
+func resourceShard() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceShardCreate,
+		ReadContext:   resourceShardRead,
+		DeleteContext: resourceShardDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Hour),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"connection_string": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The replica set of the shard as <replica set>/<host:port>[,<host:port>...]",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The shard name, the replica set name when omitted",
+			},
+			"host": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The shard members as known by the cluster",
+			},
+			"draining": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceShardCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var connectionString = data.Get("connection_string").(string)
+	if connectionString == "" {
+		return diag.Errorf("connection_string is required to add a shard")
+	}
+	command := bson.D{{Key: "addShard", Value: connectionString}}
+	if name := data.Get("name").(string); name != "" {
+		command = append(command, bson.E{Key: "name", Value: name})
+	}
+	/* addShard succeeds again when the shard is already added with the same options */
+	var result SingleResultAddShard
+	err := withRetry(ctx, "addShard "+connectionString, func(int) error {
+		return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Decode(&result)
+	})
+	if err != nil {
+		return diag.Errorf("Could not add the shard %s : %s ", connectionString, err)
+	}
+	data.SetId(result.ShardAdded)
+	return resourceShardRead(ctx, data, i)
+}
+
+func resourceShardRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var name = data.Id()
+	document, found, err := findConfigDocument(ctx, client, "shards", bson.D{{Key: "_id", Value: name}})
+	if err != nil {
+		return diag.Errorf("Could not read config.shards : %s ", err)
+	}
+	if !found {
+		log.Printf("[WARN] shard %s not found, removing from state", name)
+		data.SetId("")
+		return nil
+	}
+	var result ConfigShard
+	if err := bson.Unmarshal(document, &result); err != nil {
+		return diag.Errorf("Error decoding config.shards : %s ", err)
+	}
+
+	/* the cluster lists every member, the configured seed list is kept */
+	if data.Get("connection_string").(string) == "" {
+		if err := data.Set("connection_string", result.Host); err != nil {
+			return diag.Errorf("error setting connection_string : %s ", err)
+		}
+	}
+	if err := data.Set("name", name); err != nil {
+		return diag.Errorf("error setting name : %s ", err)
+	}
+	if err := data.Set("host", result.Host); err != nil {
+		return diag.Errorf("error setting host : %s ", err)
+	}
+	if err := data.Set("draining", result.Draining); err != nil {
+		return diag.Errorf("error setting draining : %s ", err)
+	}
+	return nil
+}
+
+func resourceShardDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	if err := drainShard(ctx, client, data.Id()); err != nil {
+		return diag.Errorf("Could not remove the shard %s : %s ", data.Id(), err)
+	}
+	return nil
+}
+
+// drainShard calls removeShard until the balancer has moved every chunk away and the shard is removed, or ctx is done.
+// movePrimaryError lists the movePrimary commands that unblock the draining of shard, towards any shard
+// that is not draining.
+func movePrimaryError(ctx context.Context, client *mongo.Client, shard string, databases []string) error {
+	var target = "<shard>"
+	document, found, err := findConfigDocument(ctx, client, "shards", bson.D{{Key: "_id", Value: bson.D{{Key: "$ne", Value: shard}}}, {Key: "draining", Value: bson.D{{Key: "$ne", Value: true}}}})
+	if err == nil && found {
+		if id, ok := document.Lookup("_id").StringValueOK(); ok {
+			target = id
+		}
+	}
+	var commands []string
+	for _, database := range databases {
+		commands = append(commands, fmt.Sprintf("db.adminCommand({ movePrimary: %q, to: %q })", database, target))
+	}
+	return fmt.Errorf("the chunks are drained but the shard is still the primary shard of %s, only movePrimary moves them : run %s then destroy again",
+		strings.Join(databases, ", "), strings.Join(commands, "; "))
+}
+
+func drainShard(ctx context.Context, client *mongo.Client, name string) error {
+	ticker := time.NewTicker(shardDrainingInterval)
+	defer ticker.Stop()
+	for {
+		var result SingleResultRemoveShard
+		err := withRetry(ctx, "removeShard "+name, func(int) error {
+			return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), bson.D{{Key: "removeShard", Value: name}})).Decode(&result)
+		})
+		/* a previous call may have completed the removal */
+		if hasErrorCode(err, errorCodeShardNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch result.State {
+		case "completed":
+			log.Printf("[INFO] shard %s removed", name)
+			return nil
+		case "started":
+			log.Printf("[INFO] shard %s draining started", name)
+		default:
+			if result.Remaining != nil {
+				log.Printf("[INFO] shard %s draining : %d chunks, %d jumbo chunks and %d databases remaining", name,
+					result.Remaining.Chunks, result.Remaining.JumboChunks, result.Remaining.Dbs)
+			}
+		}
+		/* the primary databases of the shard are only moved by movePrimary, the draining would never complete */
+		if result.State == "ongoing" && result.Remaining != nil && result.Remaining.Chunks == 0 && len(result.DbsToMove) > 0 {
+			return movePrimaryError(ctx, client, name, result.DbsToMove)
+		}
+
+		select {
+		case <-ctx.Done():
+			if result.Remaining == nil {
+				return fmt.Errorf("the draining did not complete within the delete timeout")
+			}
+			return fmt.Errorf("the draining did not complete within the delete timeout, %d chunks remaining", result.Remaining.Chunks)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"log"
+	"strings"
+	"time"
+)
+
+type ConfigCollection struct {
+	Id      string   `bson:"_id"`
+	Key     bson.Raw `bson:"key"`
+	Unique  bool     `bson:"unique"`
+	Dropped bool     `bson:"dropped"`
+}
+
+func resourceShardCollection() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceShardCollectionCreate,
+		ReadContext:   resourceShardCollectionRead,
+		DeleteContext: resourceShardCollectionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"collection": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The shard key in JSON, its field order matters",
+			},
+			"unique": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+			"num_initial_chunks": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.IntAtLeast(1)),
+				Description:      "The number of chunks created up front for an empty collection with a hashed shard key",
+			},
+			"presplit_hashed_zones": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Create the initial chunks of an empty collection from the zones of a compound hashed shard key",
+			},
+		},
+	}
+}
+
+func resourceShardCollectionCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var namespace = data.Get("database").(string) + "." + data.Get("collection").(string)
+	key, err := parseExtendedJSON(data.Get("key").(string))
+	if err != nil || key.Type != bsontype.EmbeddedDocument {
+		return diag.Errorf("the key must be a JSON object")
+	}
+
+	command := bson.D{
+		{Key: "shardCollection", Value: namespace},
+		{Key: "key", Value: key},
+		{Key: "unique", Value: data.Get("unique").(bool)},
+	}
+	if chunks, ok := data.GetOk("num_initial_chunks"); ok {
+		command = append(command, bson.E{Key: "numInitialChunks", Value: int32(chunks.(int))})
+	}
+	if data.Get("presplit_hashed_zones").(bool) {
+		command = append(command, bson.E{Key: "presplitHashedZones", Value: true})
+	}
+	/* shardCollection succeeds again when the collection is already sharded with the same options */
+	err = withRetry(ctx, "shardCollection "+namespace, func(int) error {
+		return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not shard %s : %s ", namespace, err)
+	}
+	data.SetId(base64.StdEncoding.EncodeToString([]byte(namespace)))
+	return resourceShardCollectionRead(ctx, data, i)
+}
+
+func resourceShardCollectionRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	collection, database, err := resourceShardCollectionParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	var namespace = database + "." + collection
+	document, found, err := findConfigDocument(ctx, client, "collections", bson.D{{Key: "_id", Value: namespace}})
+	if err != nil {
+		return diag.Errorf("Could not read config.collections : %s ", err)
+	}
+	var result ConfigCollection
+	if found {
+		if err := bson.Unmarshal(document, &result); err != nil {
+			return diag.Errorf("Error decoding config.collections : %s ", err)
+		}
+	}
+	if !found || result.Dropped {
+		log.Printf("[WARN] %s is not sharded, removing from state", namespace)
+		data.SetId("")
+		return nil
+	}
+
+	/* the configured key is kept while it names the same fields in the same order */
+	var key = data.Get("key").(string)
+	if !equivalentExtendedJSON(key, result.Key) {
+		if key, err = orderedExtendedJSON(result.Key); err != nil {
+			return diag.Errorf("Error encoding the shard key : %s ", err)
+		}
+	}
+	if err := data.Set("key", key); err != nil {
+		return diag.Errorf("error setting key : %s ", err)
+	}
+	if err := data.Set("unique", result.Unique); err != nil {
+		return diag.Errorf("error setting unique : %s ", err)
+	}
+	if err := data.Set("database", database); err != nil {
+		return diag.Errorf("error setting database : %s ", err)
+	}
+	if err := data.Set("collection", collection); err != nil {
+		return diag.Errorf("error setting collection : %s ", err)
+	}
+	return nil
+}
+
+/* a sharded collection cannot be unsharded, destroy only forgets it */
+func resourceShardCollectionDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	log.Printf("[INFO] collection left sharded")
+	return nil
+}
+
+func resourceShardCollectionParseId(id string) (string, string, error) {
+	result, errEncoding := base64.StdEncoding.DecodeString(id)
+
+	if errEncoding != nil {
+		return "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(result), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected database.collection", id)
+	}
+	return parts[1], parts[0], nil
+}
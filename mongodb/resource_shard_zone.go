@@ -0,0 +1,157 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"strings"
+	"time"
+)
+
+type ConfigShard struct {
+	Id       string   `bson:"_id"`
+	Host     string   `bson:"host"`
+	State    int32    `bson:"state"`
+	Draining bool     `bson:"draining"`
+	Tags     []string `bson:"tags"`
+}
+
+func resourceShardZone() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceShardZoneCreate,
+		ReadContext:   resourceShardZoneRead,
+		DeleteContext: resourceShardZoneDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"shard": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"zone": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceShardZoneCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var shard = data.Get("shard").(string)
+	var zone = data.Get("zone").(string)
+	command := bson.D{{Key: "addShardToZone", Value: shard}, {Key: "zone", Value: zone}}
+	err := withRetry(ctx, "addShardToZone "+shard, func(int) error {
+		return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not add %s to the zone %s : %s ", shard, zone, err)
+	}
+	data.SetId(base64.StdEncoding.EncodeToString([]byte(shard + "." + zone)))
+	return resourceShardZoneRead(ctx, data, i)
+}
+
+func resourceShardZoneRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	shard, zone, err := resourceShardZoneParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	document, found, err := findConfigDocument(ctx, client, "shards", bson.D{{Key: "_id", Value: shard}})
+	if err != nil {
+		return diag.Errorf("Could not read config.shards : %s ", err)
+	}
+	var result ConfigShard
+	if found {
+		if err := bson.Unmarshal(document, &result); err != nil {
+			return diag.Errorf("Error decoding config.shards : %s ", err)
+		}
+	}
+	if !found || !containsString(result.Tags, zone) {
+		log.Printf("[WARN] shard %s is not in the zone %s, removing from state", shard, zone)
+		data.SetId("")
+		return nil
+	}
+	if err := data.Set("shard", shard); err != nil {
+		return diag.Errorf("error setting shard : %s ", err)
+	}
+	if err := data.Set("zone", zone); err != nil {
+		return diag.Errorf("error setting zone : %s ", err)
+	}
+	return nil
+}
+
+func resourceShardZoneDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	shard, zone, err := resourceShardZoneParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	command := bson.D{{Key: "removeShardFromZone", Value: shard}, {Key: "zone", Value: zone}}
+	err = withRetry(ctx, "removeShardFromZone "+shard, func(int) error {
+		return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not remove %s from the zone %s : %s ", shard, zone, err)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func resourceShardZoneParseId(id string) (string, string, error) {
+	result, errEncoding := base64.StdEncoding.DecodeString(id)
+
+	if errEncoding != nil {
+		return "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(result), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected shard.zone", id)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,302 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type ViewSpecification struct {
+	Name    string `bson:"name"`
+	Type    string `bson:"type"`
+	Options struct {
+		ViewOn    string        `bson:"viewOn"`
+		Pipeline  bson.RawValue `bson:"pipeline"`
+		Collation bson.Raw      `bson:"collation,omitempty"`
+	} `bson:"options"`
+}
+
+func resourceView() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceViewCreate,
+		ReadContext:   resourceViewRead,
+		UpdateContext: resourceViewUpdate,
+		DeleteContext: resourceViewDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"view_on": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The source collection or view",
+			},
+			"pipeline": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				DiffSuppressFunc: suppressEquivalentOrderedExtendedJSON,
+				Description:      "The aggregation pipeline stages, an Extended JSON array",
+			},
+			"collation": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				DiffSuppressFunc: suppressEquivalentJSON,
+				Description:      "The default collation of the view ( JSON ), it cannot be changed in place",
+			},
+		},
+	}
+}
+
+func resourceViewCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var database = data.Get("database").(string)
+	var view = data.Get("name").(string)
+	pipeline, err := viewPipeline(data.Get("pipeline").(string))
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	command := bson.D{
+		{Key: "create", Value: view},
+		{Key: "viewOn", Value: data.Get("view_on").(string)},
+		{Key: "pipeline", Value: pipeline},
+	}
+	if collation := data.Get("collation").(string); collation != "" {
+		var document bson.D
+		if err := bson.UnmarshalExtJSON([]byte(collation), false, &document); err != nil {
+			return diag.Errorf("Error decoding collation : %s ", err)
+		}
+		command = append(command, bson.E{Key: "collation", Value: document})
+	}
+	err = withRetry(ctx, "create view "+database+"."+view, func(attempt int) error {
+		err := client.Database(database).RunCommand(ctx, withWriteConcern(client.Database(database), command)).Err()
+		/* a retried create may have been applied before the connection dropped */
+		if attempt > 1 && hasErrorCode(err, errorCodeNamespaceExists) {
+			current, found, getErr := getView(ctx, client, database, view)
+			if getErr == nil && found && current.Type == "view" &&
+				current.Options.ViewOn == data.Get("view_on").(string) && equivalentValues(current.Options.Pipeline, pipeline) {
+				return nil
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not create the view : %s ", err)
+	}
+	data.SetId(base64.StdEncoding.EncodeToString([]byte(database + "." + view)))
+	return resourceViewRead(ctx, data, i)
+}
+
+func resourceViewRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	view, database, err := resourceViewParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	specification, found, err := getView(ctx, client, database, view)
+	if err != nil {
+		return diag.Errorf("Could not read the view : %s ", err)
+	}
+	if !found {
+		data.SetId("")
+		return nil
+	}
+	if specification.Type != "view" {
+		return diag.Errorf("%s.%s is a %s, not a view", database, view, specification.Type)
+	}
+
+	/* the field order of a stage matters, $sort for one */
+	var pipeline = data.Get("pipeline").(string)
+	if configured, err := parseExtendedJSON(pipeline); err != nil || !equivalentValues(configured, specification.Options.Pipeline) {
+		if pipeline, err = orderedExtendedJSON(specification.Options.Pipeline); err != nil {
+			return diag.Errorf("Error decoding pipeline : %s ", err)
+		}
+	}
+	if err := data.Set("pipeline", pipeline); err != nil {
+		return diag.Errorf("error setting pipeline : %s ", err)
+	}
+	if err := data.Set("view_on", specification.Options.ViewOn); err != nil {
+		return diag.Errorf("error setting view_on : %s ", err)
+	}
+	/* the server completes the collation with every default field, only the configured fields are compared */
+	var collation string
+	if len(specification.Options.Collation) > 0 {
+		collation, err = formatExtendedJSON(specification.Options.Collation)
+		if err != nil {
+			return diag.Errorf("Error decoding collation : %s ", err)
+		}
+		if configured := data.Get("collation").(string); configured != "" && jsonSubset(configured, collation) {
+			collation = configured
+		}
+	}
+	if err := data.Set("collation", collation); err != nil {
+		return diag.Errorf("error setting collation : %s ", err)
+	}
+	if err := data.Set("database", database); err != nil {
+		return diag.Errorf("error setting database : %s ", err)
+	}
+	if err := data.Set("name", view); err != nil {
+		return diag.Errorf("error setting name : %s ", err)
+	}
+	return nil
+}
+
+func resourceViewUpdate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	view, database, err := resourceViewParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	pipeline, err := viewPipeline(data.Get("pipeline").(string))
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	command := bson.D{
+		{Key: "collMod", Value: view},
+		{Key: "viewOn", Value: data.Get("view_on").(string)},
+		{Key: "pipeline", Value: pipeline},
+	}
+	err = withRetry(ctx, "collMod "+database+"."+view, func(int) error {
+		return client.Database(database).RunCommand(ctx, withWriteConcern(client.Database(database), command)).Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not update the view : %s ", err)
+	}
+	return resourceViewRead(ctx, data, i)
+}
+
+func resourceViewDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	view, database, err := resourceViewParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	err = withRetry(ctx, "drop view "+database+"."+view, func(attempt int) error {
+		err := client.Database(database).RunCommand(ctx, withWriteConcern(client.Database(database), bson.D{{Key: "drop", Value: view}})).Err()
+		if hasErrorCode(err, errorCodeNamespaceNotFound) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("Could not drop the view : %s ", err)
+	}
+	return nil
+}
+
+func getView(ctx context.Context, client *mongo.Client, database string, view string) (ViewSpecification, bool, error) {
+	var specification ViewSpecification
+	var found bool
+	err := withRetry(ctx, "listCollections "+database, func(int) error {
+		cursor, err := client.Database(database).ListCollections(ctx, bson.D{{Key: "name", Value: view}})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+		found = cursor.Next(ctx)
+		if found {
+			return cursor.Decode(&specification)
+		}
+		return cursor.Err()
+	})
+	return specification, found, err
+}
+
+// viewPipeline decodes the pipeline argument, which must be an array of stages.
+func viewPipeline(pipeline string) (bson.RawValue, error) {
+	value, err := parseExtendedJSON(pipeline)
+	if err != nil {
+		return value, fmt.Errorf("Error decoding pipeline : %s ", err)
+	}
+	if value.Type != bsontype.Array {
+		return value, fmt.Errorf("the pipeline must be a JSON array of stages")
+	}
+	return value, nil
+}
+
+// jsonSubset reports whether every field of the subset JSON document has the same value in document.
+func jsonSubset(subset string, document string) bool {
+	var subsetFields, documentFields map[string]interface{}
+	if json.Unmarshal([]byte(subset), &subsetFields) != nil || json.Unmarshal([]byte(document), &documentFields) != nil {
+		return false
+	}
+	for key, value := range subsetFields {
+		if !reflect.DeepEqual(value, documentFields[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func resourceViewParseId(id string) (string, string, error) {
+	result, errEncoding := base64.StdEncoding.DecodeString(id)
+
+	if errEncoding != nil {
+		return "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(result), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected database.viewName", id)
+	}
+	return parts[1], parts[0], nil
+}
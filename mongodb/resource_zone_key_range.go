@@ -0,0 +1,242 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"strings"
+	"time"
+)
+
+type ConfigTag struct {
+	Ns  string   `bson:"ns"`
+	Min bson.Raw `bson:"min"`
+	Max bson.Raw `bson:"max"`
+	Tag string   `bson:"tag"`
+}
+
+func resourceZoneKeyRange() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceZoneKeyRangeCreate,
+		ReadContext:   resourceZoneKeyRangeRead,
+		DeleteContext: resourceZoneKeyRangeDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithConnection,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"connection": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The provider connection profile to use, defaults to the provider block",
+			},
+			"database": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"collection": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"min": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The inclusive lower bound of the range in Extended JSON, with every shard key field",
+			},
+			"max": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validateDiagFunc(validation.StringIsJSON),
+				Description:      "The exclusive upper bound of the range in Extended JSON, with every shard key field",
+			},
+			"zone": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceZoneKeyRangeCreate(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var namespace = data.Get("database").(string) + "." + data.Get("collection").(string)
+	min, err := zoneKeyRangeBound(data.Get("min").(string), "min")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	max, err := zoneKeyRangeBound(data.Get("max").(string), "max")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	if err := updateZoneKeyRange(ctx, client, namespace, min, max, data.Get("zone").(string)); err != nil {
+		return diag.Errorf("Could not add the range to the zone : %s ", err)
+	}
+	encodedMin, err := orderedExtendedJSON(min)
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	data.SetId(base64.StdEncoding.EncodeToString([]byte(namespace)) + "." + base64.StdEncoding.EncodeToString([]byte(encodedMin)))
+	return resourceZoneKeyRangeRead(ctx, data, i)
+}
+
+func resourceZoneKeyRangeRead(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutRead))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	database, collection, encodedMin, err := resourceZoneKeyRangeParseId(data.Id())
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	min, err := zoneKeyRangeBound(encodedMin, "min")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+
+	/* the bounds written by the shell hold doubles, the ranges are compared field by field rather than in the filter */
+	var namespace = database + "." + collection
+	var current *ConfigTag
+	err = withRetry(ctx, "find config.tags", func(int) error {
+		cursor, err := client.Database("config").Collection("tags").Find(ctx, bson.D{{Key: "ns", Value: namespace}})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+		current = nil
+		for cursor.Next(ctx) {
+			var tag ConfigTag
+			if err := cursor.Decode(&tag); err != nil {
+				return err
+			}
+			if equivalentDocuments(tag.Min, min) {
+				current = &tag
+				break
+			}
+		}
+		return cursor.Err()
+	})
+	if err != nil {
+		return diag.Errorf("Could not read config.tags : %s ", err)
+	}
+	if current == nil {
+		log.Printf("[WARN] no zone range of %s starts at %s, removing from state", namespace, encodedMin)
+		data.SetId("")
+		return nil
+	}
+
+	var configuredMin = data.Get("min").(string)
+	if !equivalentExtendedJSON(configuredMin, current.Min) {
+		configuredMin = encodedMin
+	}
+	var configuredMax = data.Get("max").(string)
+	if !equivalentExtendedJSON(configuredMax, current.Max) {
+		if configuredMax, err = orderedExtendedJSON(current.Max); err != nil {
+			return diag.Errorf("Error encoding max : %s ", err)
+		}
+	}
+	var values = map[string]interface{}{
+		"database":   database,
+		"collection": collection,
+		"min":        configuredMin,
+		"max":        configuredMax,
+		"zone":       current.Tag,
+	}
+	for key, value := range values {
+		if err := data.Set(key, value); err != nil {
+			return diag.Errorf("error setting %s : %s ", key, err)
+		}
+	}
+	return nil
+}
+
+func resourceZoneKeyRangeDelete(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	var config = i.(*MongoDatabaseConfiguration)
+	client, connectionError := MongoClientInit(ctx, config, data.Get("connection").(string))
+	if connectionError != nil {
+		return diag.Errorf("Error connecting to database : %s ", connectionError)
+	}
+	var namespace = data.Get("database").(string) + "." + data.Get("collection").(string)
+	min, err := zoneKeyRangeBound(data.Get("min").(string), "min")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	max, err := zoneKeyRangeBound(data.Get("max").(string), "max")
+	if err != nil {
+		return diag.Errorf("%s", err)
+	}
+	/* a null zone removes the range */
+	if err := updateZoneKeyRange(ctx, client, namespace, min, max, nil); err != nil {
+		return diag.Errorf("Could not remove the range from the zone : %s ", err)
+	}
+	return nil
+}
+
+func updateZoneKeyRange(ctx context.Context, client *mongo.Client, namespace string, min bson.Raw, max bson.Raw, zone interface{}) error {
+	command := bson.D{
+		{Key: "updateZoneKeyRange", Value: namespace},
+		{Key: "min", Value: min},
+		{Key: "max", Value: max},
+		{Key: "zone", Value: zone},
+	}
+	return withRetry(ctx, "updateZoneKeyRange "+namespace, func(int) error {
+		return client.Database("admin").RunCommand(ctx, withWriteConcern(client.Database("admin"), command)).Err()
+	})
+}
+
+func zoneKeyRangeBound(bound string, name string) (bson.Raw, error) {
+	value, err := parseExtendedJSON(bound)
+	if err != nil || value.Type != bsontype.EmbeddedDocument {
+		return nil, fmt.Errorf("%s must be a JSON object", name)
+	}
+	return value.Document(), nil
+}
+
+func resourceZoneKeyRangeParseId(id string) (string, string, string, error) {
+	encoded := strings.SplitN(id, ".", 2)
+	if len(encoded) != 2 {
+		return "", "", "", fmt.Errorf("unexpected format of ID (%s), expected base64(database.collection).base64(min)", id)
+	}
+	namespace, errEncoding := base64.StdEncoding.DecodeString(encoded[0])
+	if errEncoding != nil {
+		return "", "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	min, errEncoding := base64.StdEncoding.DecodeString(encoded[1])
+	if errEncoding != nil {
+		return "", "", "", fmt.Errorf("unexpected format of ID Error : %s", errEncoding)
+	}
+	parts := strings.SplitN(string(namespace), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || len(min) == 0 {
+		return "", "", "", fmt.Errorf("unexpected format of ID (%s), expected base64(database.collection).base64(min)", id)
+	}
+	return parts[0], parts[1], string(min), nil
+}
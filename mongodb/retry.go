@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"log"
+	"time"
+)
+
+const (
+	errorCodeUserNotFound       = 11
+	errorCodeUnauthorized       = 13
+	errorCodeAlreadyInitialized = 23
+	errorCodeNamespaceNotFound  = 26
+	errorCodeRoleNotFound       = 31
+	errorCodeNamespaceExists    = 48
+	errorCodeShardNotFound      = 70
+	errorCodeInvalidOptions     = 72
+	errorCodeRoleAlreadyExists  = 51002
+	errorCodeUserAlreadyExists  = 51003
+
+	retryInitialInterval = 500 * time.Millisecond
+	retryMaxInterval     = 10 * time.Second
+)
+
+// retryableErrorCodes are raised while a primary steps down, a member shuts down or the network is flaky.
+var retryableErrorCodes = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	89,    // NetworkTimeout
+	109,   // ConfigurationInProgress, a replSetReconfig is running
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	262,   // ExceededTimeLimit
+	308,   // CurrentConfigNotCommittedYet, the previous replSetReconfig is not replicated yet
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+var retryableErrorLabels = []string{
+	"RetryableWriteError",
+	"TransientTransactionError",
+	"NetworkError",
+}
+
+func isRetryableError(err error) bool {
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	for _, label := range retryableErrorLabels {
+		if serverErr.HasErrorLabel(label) {
+			return true
+		}
+	}
+	for _, code := range retryableErrorCodes {
+		if serverErr.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasErrorCode(err error, code int) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(code)
+}
+
+// withRetry runs operation until it succeeds, fails with a non retryable error or ctx is done.
+// The wait between two attempts doubles up to retryMaxInterval, attempt starts at 1.
+func withRetry(ctx context.Context, name string, operation func(attempt int) error) error {
+	interval := retryInitialInterval
+	for attempt := 1; ; attempt++ {
+		err := operation(attempt)
+		if err == nil || !isRetryableError(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			// cancelled ( Ctrl-C ) or out of time, the error is most likely caused by ctx itself
+			return err
+		}
+
+		log.Printf("[WARN] %s failed on attempt %d, retrying in %s : %s", name, attempt, interval, err)
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s : giving up after %d attempts : %s", name, attempt, err)
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > retryMaxInterval {
+			interval = retryMaxInterval
+		}
+	}
+}
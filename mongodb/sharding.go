@@ -0,0 +1,32 @@
+package mongodb
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// findConfigDocument reads a document of the config database of a sharded cluster, found is false when nothing matched.
+func findConfigDocument(ctx context.Context, client *mongo.Client, collection string, filter interface{}) (bson.Raw, bool, error) {
+	var document bson.Raw
+	var found = true
+	err := withRetry(ctx, "find config."+collection, func(int) error {
+		err := client.Database("config").Collection(collection).FindOne(ctx, filter).Decode(&document)
+		if err == mongo.ErrNoDocuments {
+			found = false
+			return nil
+		}
+		return err
+	})
+	return document, found, err
+}
+
+// equivalentExtendedJSON tells whether the configured Extended JSON document holds the same fields as document.
+func equivalentExtendedJSON(configured string, document bson.Raw) bool {
+	value, err := parseExtendedJSON(configured)
+	if err != nil || value.Type != bsontype.EmbeddedDocument {
+		return false
+	}
+	return equivalentDocuments(value.Document(), document)
+}
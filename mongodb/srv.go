@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// srvTXTOptions are the only options a seedlist TXT record may set.
+var srvTXTOptions = []string{"authSource", "replicaSet", "loadBalanced"}
+
+// resolveSeedlist resolves the SRV and TXT records of a mongodb+srv host into the seed list and its options.
+// The lookups are done here rather than by the driver so dns_resolver can point them at another DNS server.
+func (c *ClientConfig) resolveSeedlist(ctx context.Context) ([]string, url.Values, error) {
+	host := strings.TrimSuffix(c.Host, ".")
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 {
+		return nil, nil, fmt.Errorf("a mongodb+srv host needs at least three parts (host.domain.tld), got %s", c.Host)
+	}
+	parentDomain := "." + strings.Join(parts[1:], ".")
+
+	resolver := c.resolver()
+	_, records, err := resolver.LookupSRV(ctx, "mongodb", "tcp", host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed looking up SRV records of _mongodb._tcp.%s : %s", host, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("no SRV record found for _mongodb._tcp.%s", host)
+	}
+	var seedlist []string
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		if !strings.HasSuffix(target, parentDomain) {
+			return nil, nil, fmt.Errorf("SRV target %s is not in the %s domain", target, parentDomain[1:])
+		}
+		seedlist = append(seedlist, net.JoinHostPort(target, strconv.Itoa(int(record.Port))))
+	}
+
+	txtOptions := url.Values{}
+	txts, err := resolver.LookupTXT(ctx, host)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); !ok || !dnsErr.IsNotFound {
+			return nil, nil, fmt.Errorf("failed looking up TXT records of %s : %s", host, err)
+		}
+	}
+	if len(txts) > 1 {
+		return nil, nil, fmt.Errorf("%s has %d TXT records, at most one is allowed", host, len(txts))
+	}
+	if len(txts) == 1 {
+		txtOptions, err = url.ParseQuery(txts[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid TXT record %q : %s", txts[0], err)
+		}
+		for option := range txtOptions {
+			if !containsFold(srvTXTOptions, option) {
+				return nil, nil, fmt.Errorf("option %s is not allowed in the TXT record of %s", option, host)
+			}
+		}
+	}
+
+	return seedlist, txtOptions, nil
+}
+
+// resolver returns the system resolver, or one sending every query to dns_resolver.
+func (c *ClientConfig) resolver() *net.Resolver {
+	if c.DNSResolver == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, c.DNSResolver)
+		},
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
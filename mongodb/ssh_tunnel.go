@@ -0,0 +1,204 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type SSHTunnelConfig struct {
+	Host                  string
+	Port                  string
+	User                  string
+	PrivateKey            string
+	PrivateKeyPassphrase  string
+	UseAgent              bool
+	KnownHostsFile        string
+	InsecureIgnoreHostKey bool
+}
+
+// sshTunnel is a options.ContextDialer opening every mongo connection through a bastion.
+// The ssh session is opened on the first dial and shared by every connection afterwards.
+type sshTunnel struct {
+	config  *SSHTunnelConfig
+	forward options.ContextDialer
+
+	mu        sync.Mutex
+	client    *ssh.Client
+	agentConn net.Conn
+}
+
+func sshTunnelDialer(c *SSHTunnelConfig, forward options.ContextDialer) (options.ContextDialer, error) {
+	if c.PrivateKey == "" && !c.UseAgent {
+		return nil, errors.New("ssh_tunnel requires a private_key or use_agent = true")
+	}
+	return &sshTunnel{config: c, forward: forward}, nil
+}
+
+func (t *sshTunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	client, err := t.sshClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	result := make(chan dialResult, 1)
+	go func() {
+		conn, err := client.Dial(network, address)
+		result <- dialResult{conn, err}
+	}()
+
+	select {
+	case r := <-result:
+		if r.err != nil {
+			if sshTransportFailed(r.err) {
+				// the session is dead, reconnect on the next dial
+				t.reset(client)
+			}
+			return nil, fmt.Errorf("ssh tunnel could not reach %s : %s", address, r.err)
+		}
+		return r.conn, nil
+	case <-ctx.Done():
+		go func() {
+			if r := <-result; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+func (t *sshTunnel) sshClient(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	clientConfig, agentConn, err := t.config.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	address := net.JoinHostPort(t.config.Host, t.config.Port)
+	conn, err := t.forward.DialContext(ctx, "tcp", address)
+	if err != nil {
+		closeAgent(agentConn)
+		return nil, fmt.Errorf("could not reach ssh host %s : %s", address, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		_ = conn.Close()
+		closeAgent(agentConn)
+		return nil, fmt.Errorf("ssh handshake with %s failed : %s", address, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	t.client = ssh.NewClient(sshConn, channels, requests)
+	t.agentConn = agentConn
+	return t.client, nil
+}
+
+func (t *sshTunnel) reset(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client == client {
+		_ = t.client.Close()
+		t.client = nil
+		closeAgent(t.agentConn)
+		t.agentConn = nil
+	}
+}
+
+// sshTransportFailed tells a dead ssh session apart from a forward refused by the bastion,
+// an unreachable member must not break the connections to the healthy ones.
+func sshTransportFailed(err error) bool {
+	var rejected *ssh.OpenChannelError
+	if errors.As(err, &rejected) {
+		return false
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)
+}
+
+func closeAgent(agentConn net.Conn) {
+	if agentConn != nil {
+		_ = agentConn.Close()
+	}
+}
+
+// clientConfig also returns the ssh agent connection when use_agent is set, the caller closes it.
+func (c *SSHTunnelConfig) clientConfig() (*ssh.ClientConfig, net.Conn, error) {
+	var auth []ssh.AuthMethod
+	var agentConn net.Conn
+
+	if c.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if c.PrivateKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(c.PrivateKey), []byte(c.PrivateKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(c.PrivateKey))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed parsing ssh private_key : %s", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if c.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, errors.New("use_agent is set but SSH_AUTH_SOCK is empty")
+		}
+		var err error
+		agentConn, err = net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not reach the ssh agent : %s", err)
+		}
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+
+	hostKeyCallback, err := c.hostKeyCallback()
+	if err != nil {
+		closeAgent(agentConn)
+		return nil, nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User:            c.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, agentConn, nil
+}
+
+func (c *SSHTunnelConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if c.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	knownHostsFile := c.KnownHostsFile
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not locate ~/.ssh/known_hosts : %s", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	if _, err := os.Stat(knownHostsFile); err != nil {
+		return nil, fmt.Errorf("failed reading known_hosts_file : %s", err)
+	}
+	return knownhosts.New(knownHostsFile)
+}
@@ -0,0 +1,223 @@
+package mongodb
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// customTLS reports whether the driver default TLS settings have to be replaced.
+func (c *ClientConfig) customTLS() bool {
+	return c.Certificate != "" || c.CAFile != "" || c.CADirectory != "" || c.UseSystemCA ||
+		c.ClientCertificateFile != "" || c.TLSServerName != "" || c.TLSAllowInvalidHostnames
+}
+
+func (c *ClientConfig) tlsConfig() (*tls.Config, error) {
+	/* As of version 1.2.1, the MongoDB Go Driver will only use the first CA server certificate found in sslcertificateauthorityfile.
+	   The code below addresses this limitation by manually appending all server certificates found in sslcertificateauthorityfile
+	   to a custom TLS configuration used during client creation. */
+
+	tlsConfig := new(tls.Config)
+
+	/*
+		@Since: v0.0.9
+		verify certificate
+	*/
+	tlsConfig.InsecureSkipVerify = c.InsecureSkipVerify
+
+	/*
+		@Since: v0.0.7
+		add certificate support for documentDB
+	*/
+	rootCAs, err := c.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.RootCAs = rootCAs
+	tlsConfig.ServerName = c.TLSServerName
+
+	/*
+		only skip the hostname check, the chain is still verified against the provided CA
+		( e.g. reaching documentDB through a tunnel on localhost )
+	*/
+	if c.TLSAllowInvalidHostnames && !c.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyPeerCertificateChain(tlsConfig.RootCAs)
+	}
+
+	if c.ClientCertificateFile != "" {
+		keyFile := c.ClientKeyFile
+		if keyFile == "" {
+			// the key is usually bundled with the certificate ( tlsCertificateKeyFile )
+			keyFile = c.ClientCertificateFile
+		}
+		clientCert, err := tls.LoadX509KeyPair(c.ClientCertificateFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading client certificate %s : %s", c.ClientCertificateFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+// rootCAs returns the pool used to verify the server certificate.
+// A nil pool means the system roots are used.
+func (c *ClientConfig) rootCAs() (*x509.CertPool, error) {
+	if c.Certificate == "" && c.CAFile == "" && c.CADirectory == "" {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if c.UseSystemCA {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Printf("[WARN] could not load the system certificate pool : %s", err)
+		} else {
+			pool = systemPool
+		}
+	}
+
+	report := &certificateReport{}
+	if c.Certificate != "" {
+		if err := report.append(pool, "certificate", []byte(c.Certificate)); err != nil {
+			return nil, err
+		}
+	}
+	if c.CAFile != "" {
+		content, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading ca_file : %s", err)
+		}
+		if err := report.append(pool, "ca_file "+c.CAFile, content); err != nil {
+			return nil, err
+		}
+	}
+	if c.CADirectory != "" {
+		if err := report.appendDirectory(pool, c.CADirectory); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, line := range report.parsed {
+		log.Printf("[DEBUG] trusted CA %s", line)
+	}
+	return pool, nil
+}
+
+// certificateReport keeps track of the certificates parsed so far, to explain what was loaded when a source fails.
+type certificateReport struct {
+	parsed []string
+}
+
+func (r *certificateReport) append(pool *x509.CertPool, source string, content []byte) error {
+	var found int
+	var failures []string
+	rest := content
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			failures = append(failures, fmt.Sprintf("skipped %s block", block.Type))
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid certificate : %s", err))
+			continue
+		}
+		pool.AddCert(cert)
+		found++
+		r.parsed = append(r.parsed, fmt.Sprintf("%s : %s", source, cert.Subject))
+	}
+	if found == 0 {
+		return r.error(fmt.Sprintf("no certificate could be parsed from %s", source), failures)
+	}
+	return nil
+}
+
+func (r *certificateReport) appendDirectory(pool *x509.CertPool, directory string) error {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return fmt.Errorf("failed reading ca_directory : %s", err)
+	}
+	var names []string
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".pem", ".crt", ".cer":
+			names = append(names, file.Name())
+		}
+	}
+	if len(names) == 0 {
+		return r.error(fmt.Sprintf("no .pem, .crt or .cer file found in ca_directory %s", directory), nil)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(directory, name)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed reading %s : %s", path, err)
+		}
+		if err := r.append(pool, "ca_directory "+path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *certificateReport) error(summary string, failures []string) error {
+	var message strings.Builder
+	message.WriteString(summary)
+	for _, failure := range failures {
+		message.WriteString("\n  - " + failure)
+	}
+	if len(r.parsed) == 0 {
+		message.WriteString("\nno certificate parsed so far")
+	} else {
+		message.WriteString("\ncertificates parsed so far :")
+		for _, line := range r.parsed {
+			message.WriteString("\n  - " + line)
+		}
+	}
+	return errors.New(message.String())
+}
+
+// verifyPeerCertificateChain verifies the presented chain against roots without checking the hostname.
+// A nil roots pool falls back to the system roots.
+func verifyPeerCertificateChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("no server certificate presented")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("failed parsing server certificate : %s", err)
+			}
+			certs[i] = cert
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
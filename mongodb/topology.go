@@ -0,0 +1,162 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net"
+)
+
+type SingleResultIsMaster struct {
+	IsMaster bool     `bson:"ismaster"`
+	SetName  string   `bson:"setName"`
+	Primary  string   `bson:"primary"`
+	Me       string   `bson:"me"`
+	Hosts    []string `bson:"hosts"`
+	Passives []string `bson:"passives"`
+	Arbiters []string `bson:"arbiters"`
+	Msg      string   `bson:"msg"`
+
+	MaxWireVersion int32 `bson:"maxWireVersion"`
+}
+
+type SingleResultBuildInfo struct {
+	Version      string  `bson:"version"`
+	VersionArray []int32 `bson:"versionArray"`
+	GitVersion   string  `bson:"gitVersion"`
+}
+
+type SingleResultFeatureCompatibilityVersion struct {
+	FeatureCompatibilityVersion struct {
+		Version string `bson:"version"`
+	} `bson:"featureCompatibilityVersion"`
+}
+
+func isMaster(ctx context.Context, client *mongo.Client) (SingleResultIsMaster, error) {
+	var decodedResult SingleResultIsMaster
+	err := withRetry(ctx, "isMaster", func(int) error {
+		// isMaster rather than hello, hello needs 4.4.2+
+		return client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&decodedResult)
+	})
+	return decodedResult, err
+}
+
+func buildInfo(ctx context.Context, client *mongo.Client) (SingleResultBuildInfo, error) {
+	var decodedResult SingleResultBuildInfo
+	err := withRetry(ctx, "buildInfo", func(int) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&decodedResult)
+	})
+	return decodedResult, err
+}
+
+// featureCompatibilityVersion returns the featureCompatibilityVersion of a mongod or, as mongos does not report it,
+// the one the config servers record in admin.system.version.
+func featureCompatibilityVersion(ctx context.Context, client *mongo.Client) (string, error) {
+	hello, err := isMaster(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	if hello.Msg == "isdbgrid" {
+		return clusterFeatureCompatibilityVersion(ctx, client)
+	}
+	var decodedResult SingleResultFeatureCompatibilityVersion
+	err = withRetry(ctx, "getParameter featureCompatibilityVersion", func(int) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{{Key: "getParameter", Value: 1}, {Key: "featureCompatibilityVersion", Value: 1}}).Decode(&decodedResult)
+	})
+	return decodedResult.FeatureCompatibilityVersion.Version, err
+}
+
+// clusterFeatureCompatibilityVersion reads the featureCompatibilityVersion document of the config servers through a mongos,
+// the admin database of a sharded cluster lives on the config servers.
+func clusterFeatureCompatibilityVersion(ctx context.Context, client *mongo.Client) (string, error) {
+	var document struct {
+		Version string `bson:"version"`
+	}
+	err := withRetry(ctx, "find admin.system.version", func(int) error {
+		return client.Database("admin").Collection("system.version").FindOne(ctx, bson.D{{Key: "_id", Value: "featureCompatibilityVersion"}}).Decode(&document)
+	})
+	if err == mongo.ErrNoDocuments {
+		return "", errors.New("the config servers hold no featureCompatibilityVersion document")
+	}
+	if hasErrorCode(err, errorCodeUnauthorized) {
+		return "", fmt.Errorf("mongos does not report the featureCompatibilityVersion, reading it from the config servers needs the find action on admin.system.version : %w", err)
+	}
+	return document.Version, err
+}
+
+// replicaSetMembers returns the data bearing members of the replica set config, hidden ones included,
+// or nil when client is not connected to a replica set.
+func replicaSetMembers(ctx context.Context, client *mongo.Client) ([]string, error) {
+	result, err := isMaster(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if result.SetName == "" {
+		return nil, nil
+	}
+	/* isMaster does not list the hidden members */
+	config, err := getReplicaSetConfig(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	for _, member := range documentArray(config, "members") {
+		if arbiterOnly, _ := documentValue(member, "arbiterOnly"); arbiterOnly == true {
+			continue
+		}
+		host, _ := documentValue(member, "host")
+		members = append(members, fmt.Sprintf("%v", host))
+	}
+	return members, nil
+}
+
+// memberClient connects directly to a single member of the deployment of conf, the caller disconnects it.
+func memberClient(ctx context.Context, conf *MongoDatabaseConfiguration, member string) (*mongo.Client, error) {
+	host, port, err := net.SplitHostPort(member)
+	if err != nil {
+		return nil, err
+	}
+	config := *conf.Config
+	config.Host = host
+	config.Port = port
+	config.Srv = false
+	config.ReplicaSet = ""
+	config.Direct = true
+
+	ctx, cancel := context.WithTimeout(ctx, conf.MaxConnLifetime)
+	defer cancel()
+	client, err := config.MongoClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(ctx); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client, nil
+}
+
+// eachMember runs operation against every member of a replica set, or against client alone on other topologies.
+func eachMember(ctx context.Context, conf *MongoDatabaseConfiguration, client *mongo.Client, operation func(member string, client *mongo.Client) error) error {
+	members, err := replicaSetMembers(ctx, client)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return operation("", client)
+	}
+	for _, member := range members {
+		direct, err := memberClient(ctx, conf, member)
+		if err != nil {
+			return err
+		}
+		err = operation(member, direct)
+		_ = direct.Disconnect(context.Background())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}